@@ -0,0 +1,391 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/youwkey/alfred-go/internal/shape"
+)
+
+// DefaultBadgeSize is the width and height in pixels of generated badges.
+const DefaultBadgeSize = 128
+
+// badgeCacheDir is the directory in the workflow cache storing generated badges.
+const badgeCacheDir = "badges"
+
+// BadgeStatus is the state shown by a status dot.
+type BadgeStatus int
+
+// badge statuses.
+const (
+	BadgeStatusUnknown BadgeStatus = iota
+	BadgeStatusOK
+	BadgeStatusWarning
+	BadgeStatusError
+)
+
+// Color returns the color of the status dot.
+//
+//nolint:gomnd // colors
+func (s BadgeStatus) Color() color.NRGBA {
+	switch s {
+	case BadgeStatusOK:
+		return color.NRGBA{R: 0x34, G: 0xc7, B: 0x59, A: 0xff}
+	case BadgeStatusWarning:
+		return color.NRGBA{R: 0xff, G: 0xcc, B: 0x00, A: 0xff}
+	case BadgeStatusError:
+		return color.NRGBA{R: 0xff, G: 0x3b, B: 0x30, A: 0xff}
+	default:
+		return color.NRGBA{R: 0x8e, G: 0x8e, B: 0x93, A: 0xff}
+	}
+}
+
+// BadgeGenerator renders PNG icons into a cache directory. Files are named
+// after a hash of what they show, so a badge is rendered only once.
+type BadgeGenerator struct {
+	dir  string
+	size int
+}
+
+// NewBadgeGenerator returns a BadgeGenerator writing to the badges directory of CacheDir.
+func NewBadgeGenerator() *BadgeGenerator {
+	dir := ""
+	if cache := CacheDir(); cache != "" {
+		dir = filepath.Join(cache, badgeCacheDir)
+	}
+
+	return &BadgeGenerator{dir: dir, size: DefaultBadgeSize}
+}
+
+// Dir sets the directory storing the badges.
+func (g *BadgeGenerator) Dir(dir string) *BadgeGenerator {
+	g.dir = dir
+
+	return g
+}
+
+// Size sets the width and height in pixels of initials badges.
+func (g *BadgeGenerator) Size(size int) *BadgeGenerator {
+	g.size = size
+
+	return g
+}
+
+// Initials returns a rounded square showing the initials of name, colored
+// after name so the same name always gets the same color.
+func (g *BadgeGenerator) Initials(name string) (*Icon, error) {
+	return g.InitialsColor(name, BadgeColor(name))
+}
+
+// InitialsColor is like Initials with the background color bg.
+//
+// The initials are the first letters or digits of the first two words of name,
+// in white on dark colors and black on light colors.
+func (g *BadgeGenerator) InitialsColor(name string, bg color.Color) (*Icon, error) {
+	text := BadgeInitials(name)
+	bgc := color.NRGBAModel.Convert(bg).(color.NRGBA) //nolint:forcetypeassert // NRGBAModel returns NRGBA
+
+	return g.render(fmt.Sprintf("initials:%d:%s:%v", g.size, text, bgc), func() (image.Image, error) {
+		img := shape.RoundedSquare(g.size, g.size/5, func(x, y int) color.NRGBA { //nolint:gomnd // corner radius of macOS icons
+			return bgc
+		})
+
+		fg := color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+		if ThemeFromBackground(fmt.Sprintf("rgb(%d,%d,%d)", bgc.R, bgc.G, bgc.B)) == ThemeLight {
+			fg = color.NRGBA{A: 0xff}
+		}
+
+		drawText(img, text, fg)
+
+		return img, nil
+	})
+}
+
+// StatusDot returns the PNG image at path with a dot of the status color in
+// its bottom right corner.
+func (g *BadgeGenerator) StatusDot(path string, status BadgeStatus) (*Icon, error) {
+	return g.Dot(path, status.Color())
+}
+
+// Dot is like StatusDot with any color.
+func (g *BadgeGenerator) Dot(path string, c color.Color) (*Icon, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // os errors include the path
+	}
+
+	dot := color.NRGBAModel.Convert(c).(color.NRGBA) //nolint:forcetypeassert // NRGBAModel returns NRGBA
+	key := fmt.Sprintf("dot:%s:%d:%d:%v", path, info.Size(), info.ModTime().UnixNano(), dot)
+
+	return g.render(key, func() (image.Image, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err //nolint:wrapcheck // os errors include the path
+		}
+		defer f.Close()
+
+		base, err := png.Decode(f)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", path, err)
+		}
+
+		b := base.Bounds()
+		img := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(img, img.Bounds(), base, b.Min, draw.Src)
+
+		size := b.Dx()
+		if b.Dy() < size {
+			size = b.Dy()
+		}
+
+		r := size / 6       //nolint:gomnd // dot radius
+		ring := r + size/32 //nolint:gomnd // white ring around the dot
+		cx, cy := b.Dx()-ring-1, b.Dy()-ring-1
+
+		fillCircle(img, cx, cy, ring, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+		fillCircle(img, cx, cy, r, dot)
+
+		return img, nil
+	})
+}
+
+func (g *BadgeGenerator) render(key string, paint func() (image.Image, error)) (*Icon, error) {
+	if g.dir == "" {
+		return nil, ErrNoWorkflowDir
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	file := filepath.Join(g.dir, "badge-"+hex.EncodeToString(sum[:8])+".png")
+
+	if _, err := os.Stat(file); err == nil {
+		return NewIcon(file), nil
+	}
+
+	img, err := paint()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := EnsureDir(g.dir); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(g.dir, ".badge-*")
+	if err != nil {
+		return nil, err //nolint:wrapcheck // os errors include the path
+	}
+	defer os.Remove(tmp.Name())
+
+	err = png.Encode(tmp, img)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("encode badge: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return nil, err //nolint:wrapcheck // os errors include the path
+	}
+
+	return NewIcon(file), nil
+}
+
+// BadgeInitials returns the upper case first letters or digits of the first two words of name.
+func BadgeInitials(name string) string {
+	const maxInitials = 2
+
+	var b strings.Builder
+
+	n := 0
+
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if n == maxInitials {
+			break
+		}
+
+		b.WriteRune(unicode.ToUpper([]rune(word)[0]))
+		n++
+	}
+
+	return b.String()
+}
+
+// BadgeColor returns a color chosen from a palette after a hash of name.
+//
+//nolint:gomnd // palette
+func BadgeColor(name string) color.NRGBA {
+	palette := []color.NRGBA{
+		{R: 0xe5, G: 0x39, B: 0x35, A: 0xff},
+		{R: 0xd8, G: 0x1b, B: 0x60, A: 0xff},
+		{R: 0x8e, G: 0x24, B: 0xaa, A: 0xff},
+		{R: 0x5e, G: 0x35, B: 0xb1, A: 0xff},
+		{R: 0x39, G: 0x49, B: 0xab, A: 0xff},
+		{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff},
+		{R: 0x00, G: 0x89, B: 0x7b, A: 0xff},
+		{R: 0x43, G: 0xa0, B: 0x47, A: 0xff},
+		{R: 0xf4, G: 0x51, B: 0x1e, A: 0xff},
+		{R: 0x6d, G: 0x4c, B: 0x41, A: 0xff},
+		{R: 0x54, G: 0x6e, B: 0x7a, A: 0xff},
+		{R: 0xfd, G: 0xd8, B: 0x35, A: 0xff},
+	}
+
+	sum := sha256.Sum256([]byte(name))
+
+	return palette[int(sum[0])%len(palette)]
+}
+
+func fillCircle(img *image.NRGBA, cx, cy, r int, c color.NRGBA) {
+	for y := cy - r; y <= cy+r; y++ {
+		for x := cx - r; x <= cx+r; x++ {
+			if dx, dy := x-cx, y-cy; dx*dx+dy*dy <= r*r {
+				img.SetNRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+// glyph size of the built-in bitmap font.
+const (
+	glyphWidth  = 5
+	glyphHeight = 7
+)
+
+// drawText draws text centered on img with the built-in bitmap font.
+func drawText(img *image.NRGBA, text string, c color.NRGBA) {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return
+	}
+
+	size := img.Bounds().Dx()
+	columns := len(runes)*(glyphWidth+1) - 1
+
+	// The text takes at most 60% of the width and 45% of the height.
+	scale := size * 60 / 100 / columns                 //nolint:gomnd // text width
+	if s := size * 45 / 100 / glyphHeight; s < scale { //nolint:gomnd // text height
+		scale = s
+	}
+
+	if scale < 1 {
+		scale = 1
+	}
+
+	x0 := (size - columns*scale) / 2                  //nolint:gomnd // centered
+	y0 := (img.Bounds().Dy() - glyphHeight*scale) / 2 //nolint:gomnd // centered
+
+	for i, r := range runes {
+		rows := glyph(r)
+
+		for row := 0; row < glyphHeight; row++ {
+			for col := 0; col < glyphWidth; col++ {
+				if rows[row]&(1<<(glyphWidth-1-col)) == 0 {
+					continue
+				}
+
+				x := x0 + (i*(glyphWidth+1)+col)*scale
+				y := y0 + row*scale
+
+				draw.Draw(img, image.Rect(x, y, x+scale, y+scale), image.NewUniform(c), image.Point{}, draw.Src)
+			}
+		}
+	}
+}
+
+// glyph returns the rows of the 5x7 bitmap of r, or of "?" for unsupported runes.
+//
+//nolint:gomnd,cyclop,funlen // font data
+func glyph(r rune) [glyphHeight]uint8 {
+	switch r {
+	case 'A':
+		return [glyphHeight]uint8{0x0e, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11}
+	case 'B':
+		return [glyphHeight]uint8{0x1e, 0x11, 0x11, 0x1e, 0x11, 0x11, 0x1e}
+	case 'C':
+		return [glyphHeight]uint8{0x0e, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0e}
+	case 'D':
+		return [glyphHeight]uint8{0x1c, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1c}
+	case 'E':
+		return [glyphHeight]uint8{0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x1f}
+	case 'F':
+		return [glyphHeight]uint8{0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x10}
+	case 'G':
+		return [glyphHeight]uint8{0x0e, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0f}
+	case 'H':
+		return [glyphHeight]uint8{0x11, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11}
+	case 'I':
+		return [glyphHeight]uint8{0x0e, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0e}
+	case 'J':
+		return [glyphHeight]uint8{0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0c}
+	case 'K':
+		return [glyphHeight]uint8{0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11}
+	case 'L':
+		return [glyphHeight]uint8{0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1f}
+	case 'M':
+		return [glyphHeight]uint8{0x11, 0x1b, 0x15, 0x15, 0x11, 0x11, 0x11}
+	case 'N':
+		return [glyphHeight]uint8{0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11}
+	case 'O':
+		return [glyphHeight]uint8{0x0e, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e}
+	case 'P':
+		return [glyphHeight]uint8{0x1e, 0x11, 0x11, 0x1e, 0x10, 0x10, 0x10}
+	case 'Q':
+		return [glyphHeight]uint8{0x0e, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0d}
+	case 'R':
+		return [glyphHeight]uint8{0x1e, 0x11, 0x11, 0x1e, 0x14, 0x12, 0x11}
+	case 'S':
+		return [glyphHeight]uint8{0x0f, 0x10, 0x10, 0x0e, 0x01, 0x01, 0x1e}
+	case 'T':
+		return [glyphHeight]uint8{0x1f, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04}
+	case 'U':
+		return [glyphHeight]uint8{0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e}
+	case 'V':
+		return [glyphHeight]uint8{0x11, 0x11, 0x11, 0x11, 0x11, 0x0a, 0x04}
+	case 'W':
+		return [glyphHeight]uint8{0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0a}
+	case 'X':
+		return [glyphHeight]uint8{0x11, 0x11, 0x0a, 0x04, 0x0a, 0x11, 0x11}
+	case 'Y':
+		return [glyphHeight]uint8{0x11, 0x11, 0x11, 0x0a, 0x04, 0x04, 0x04}
+	case 'Z':
+		return [glyphHeight]uint8{0x1f, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1f}
+	case '0':
+		return [glyphHeight]uint8{0x0e, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0e}
+	case '1':
+		return [glyphHeight]uint8{0x04, 0x0c, 0x04, 0x04, 0x04, 0x04, 0x0e}
+	case '2':
+		return [glyphHeight]uint8{0x0e, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1f}
+	case '3':
+		return [glyphHeight]uint8{0x1f, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0e}
+	case '4':
+		return [glyphHeight]uint8{0x02, 0x06, 0x0a, 0x12, 0x1f, 0x02, 0x02}
+	case '5':
+		return [glyphHeight]uint8{0x1f, 0x10, 0x1e, 0x01, 0x01, 0x11, 0x0e}
+	case '6':
+		return [glyphHeight]uint8{0x06, 0x08, 0x10, 0x1e, 0x11, 0x11, 0x0e}
+	case '7':
+		return [glyphHeight]uint8{0x1f, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08}
+	case '8':
+		return [glyphHeight]uint8{0x0e, 0x11, 0x11, 0x0e, 0x11, 0x11, 0x0e}
+	case '9':
+		return [glyphHeight]uint8{0x0e, 0x11, 0x11, 0x0f, 0x01, 0x02, 0x0c}
+	default:
+		return [glyphHeight]uint8{0x0e, 0x11, 0x01, 0x02, 0x04, 0x00, 0x04}
+	}
+}
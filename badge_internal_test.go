@@ -0,0 +1,193 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func decodeTestPNG(t *testing.T, path string) image.Image {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return img
+}
+
+func TestBadgeInitials(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in  string
+		out string
+	}
+
+	tests := []Test{
+		{in: "", out: ""},
+		{in: "alfred", out: "A"},
+		{in: "Alfred Workflow", out: "AW"},
+		{in: "go alfred workflow", out: "GA"},
+		{in: "  john-doe ", out: "JD"},
+		{in: "42 things", out: "4T"},
+		{in: "élan vital", out: "ÉV"},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:BadgeInitials", i), func(t *testing.T) {
+			t.Parallel()
+
+			if got := BadgeInitials(test.in); got != test.out {
+				t.Errorf("#%d: got: %q want: %q", i, got, test.out)
+			}
+		})
+	}
+}
+
+func TestBadgeColor(t *testing.T) {
+	t.Parallel()
+
+	if a, b := BadgeColor("alfred"), BadgeColor("alfred"); a != b {
+		t.Errorf("got: %v want: %v", a, b)
+	}
+
+	seen := make(map[color.NRGBA]bool)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		seen[BadgeColor(name)] = true
+	}
+
+	if len(seen) < 2 {
+		t.Errorf("got: %d colors want: several", len(seen))
+	}
+}
+
+func TestBadgeGenerator_Initials(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	g := NewBadgeGenerator().Dir(dir).Size(64)
+	bg := color.NRGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xff}
+
+	icon, err := g.InitialsColor("Alfred Workflow", bg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := g.InitialsColor("alfred workflow", bg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if icon.path != again.path {
+		t.Errorf("got: %q want: %q", again.path, icon.path)
+	}
+
+	if filepath.Dir(icon.path) != dir || filepath.Ext(icon.path) != ".png" {
+		t.Errorf("got: %q want: png in %q", icon.path, dir)
+	}
+
+	other, err := g.InitialsColor("Other", bg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if other.path == icon.path {
+		t.Errorf("got: %q want: another file", other.path)
+	}
+
+	img := decodeTestPNG(t, icon.path)
+
+	if got := img.Bounds().Dx(); got != 64 {
+		t.Errorf("got: %d want: %d", got, 64)
+	}
+
+	if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+		t.Errorf("corner: got: alpha %d want: transparent", a)
+	}
+
+	if got := color.NRGBAModel.Convert(img.At(32, 4)); got != bg {
+		t.Errorf("edge: got: %v want: %v", got, bg)
+	}
+
+	white := 0
+
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			if color.NRGBAModel.Convert(img.At(x, y)) == (color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+				white++
+			}
+		}
+	}
+
+	if white == 0 {
+		t.Error("got: no text pixels want: white initials")
+	}
+}
+
+func TestBadgeGenerator_StatusDot(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	g := NewBadgeGenerator().Dir(filepath.Join(dir, "badges"))
+
+	base, err := g.InitialsColor("AB", color.NRGBA{B: 0xff, A: 0xff})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := g.StatusDot(base.path, BadgeStatusOK)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failed, err := g.StatusDot(base.path, BadgeStatusError)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok.path == failed.path || ok.path == base.path {
+		t.Errorf("got: %q, %q want: distinct files", ok.path, failed.path)
+	}
+
+	img := decodeTestPNG(t, ok.path)
+	size := DefaultBadgeSize
+	ring := size/6 + size/32
+
+	if got := color.NRGBAModel.Convert(img.At(size-ring-1, size-ring-1)); got != BadgeStatusOK.Color() {
+		t.Errorf("dot: got: %v want: %v", got, BadgeStatusOK.Color())
+	}
+
+	if got := color.NRGBAModel.Convert(img.At(size/2, size/2)); got == BadgeStatusOK.Color() {
+		t.Errorf("center: got: %v want: base image", got)
+	}
+
+	if _, err := g.StatusDot(filepath.Join(dir, "missing.png"), BadgeStatusOK); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got: %v want: %v", err, os.ErrNotExist)
+	}
+}
+
+func TestBadgeGenerator_NoDir(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewBadgeGenerator().Dir("").Initials("a"); !errors.Is(err, ErrNoWorkflowDir) {
+		t.Errorf("got: %v want: %v", err, ErrNoWorkflowDir)
+	}
+}
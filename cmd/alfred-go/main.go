@@ -0,0 +1,91 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Command alfred-go is a development tool for Alfred workflows written with alfred-go.
+//
+// Usage:
+//
+//	alfred-go <command> [arguments]
+//
+// The commands are:
+//
+//	new        scaffold a new workflow project
+//	package    build a .alfredworkflow archive
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// errUsage reports that the command line could not be understood.
+var errUsage = errors.New("usage error")
+
+type command struct {
+	name  string
+	short string
+	run   func(args []string, stdout, stderr io.Writer) error
+}
+
+func commands() []command {
+	return []command{
+		{name: "new", short: "scaffold a new workflow project", run: runNew},
+		{name: "package", short: "build a .alfredworkflow archive", run: runPackage},
+	}
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintf(os.Stderr, "alfred-go: %v\n", err)
+		}
+
+		os.Exit(2) //nolint:gomnd // conventional exit code for usage and runtime errors
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		usage(stderr)
+
+		return errUsage
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+
+		return nil
+	}
+
+	fmt.Fprintf(stderr, "alfred-go: unknown command %q\n", args[0])
+	usage(stderr)
+
+	return errUsage
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "alfred-go is a tool for developing Alfred workflows in Go.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "\talfred-go <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "The commands are:")
+	fmt.Fprintln(w)
+
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "\t%-8s %s\n", cmd.name, cmd.short)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Use "alfred-go <command> -h" for more information about a command.`)
+}
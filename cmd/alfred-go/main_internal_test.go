@@ -0,0 +1,47 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		args   []string
+		err    error
+		stdout string
+		stderr string
+	}
+
+	tests := []Test{
+		{args: []string{}, err: errUsage, stderr: "Usage:"},
+		{args: []string{"help"}, stdout: "The commands are:"},
+		{args: []string{"unknown"}, err: errUsage, stderr: `unknown command "unknown"`},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:run", i), func(t *testing.T) {
+			t.Parallel()
+			var stdout, stderr bytes.Buffer
+			if err := run(test.args, &stdout, &stderr); !errors.Is(err, test.err) {
+				t.Errorf("#%d: got: %v want: %v", i, err, test.err)
+			}
+			if !strings.Contains(stdout.String(), test.stdout) {
+				t.Errorf("#%d: stdout: got: %s want: %s", i, stdout.String(), test.stdout)
+			}
+			if !strings.Contains(stderr.String(), test.stderr) {
+				t.Errorf("#%d: stderr: got: %s want: %s", i, stderr.String(), test.stderr)
+			}
+		})
+	}
+}
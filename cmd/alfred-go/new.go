@@ -0,0 +1,239 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/youwkey/alfred-go"
+	"github.com/youwkey/alfred-go/internal/shape"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// errDirNotEmpty reports that the scaffold target already has contents.
+var errDirNotEmpty = errors.New("directory is not empty")
+
+const (
+	dirPerm  = 0o755
+	filePerm = 0o644
+	iconSize = 256
+)
+
+// project describes the workflow to scaffold.
+type project struct {
+	Dir         string
+	Name        string
+	Description string
+	Keyword     string
+	BundleID    string
+	Author      string
+	Module      string
+}
+
+// scaffoldFile maps a template to the file it renders.
+type scaffoldFile struct {
+	template string
+	path     string
+}
+
+func scaffoldFiles() []scaffoldFile {
+	return []scaffoldFile{
+		{template: "main.go.tmpl", path: "main.go"},
+		{template: "main_test.go.tmpl", path: "main_test.go"},
+		{template: "go.mod.tmpl", path: "go.mod"},
+		{template: "Makefile.tmpl", path: "Makefile"},
+		{template: "gitignore.tmpl", path: ".gitignore"},
+		{template: "alfredignore.tmpl", path: ".alfredignore"},
+	}
+}
+
+func runNew(args []string, stdout, stderr io.Writer) error {
+	p := project{}
+
+	fs := flag.NewFlagSet("new", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&p.Name, "name", "", "workflow name (default: directory name)")
+	fs.StringVar(&p.Description, "description", "", "workflow description")
+	fs.StringVar(&p.Keyword, "keyword", "", "Script Filter keyword (default: derived from name)")
+	fs.StringVar(&p.BundleID, "bundleid", "", "workflow bundle ID (default: com.example.<keyword>)")
+	fs.StringVar(&p.Author, "author", "", "workflow author (default: current user)")
+	fs.StringVar(&p.Module, "module", "", "Go module path (default: keyword)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: alfred-go new [flags] <dir>")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return errUsage
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+
+		return errUsage
+	}
+
+	p.Dir = fs.Arg(0)
+
+	if err := p.fillDefaults(); err != nil {
+		return err
+	}
+
+	if err := scaffold(p); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "created workflow %q in %s\n", p.Name, p.Dir)
+	fmt.Fprintln(stdout, "next steps:")
+	fmt.Fprintf(stdout, "\tcd %s && go mod tidy && make\n", p.Dir)
+
+	return nil
+}
+
+func (p *project) fillDefaults() error {
+	if p.Name == "" {
+		abs, err := filepath.Abs(p.Dir)
+		if err != nil {
+			return fmt.Errorf("resolve directory: %w", err)
+		}
+
+		p.Name = filepath.Base(abs)
+	}
+
+	if p.Keyword == "" {
+		p.Keyword = keywordFromName(p.Name)
+	}
+
+	if p.BundleID == "" {
+		p.BundleID = "com.example." + p.Keyword
+	}
+
+	if p.Author == "" {
+		if u, err := user.Current(); err == nil {
+			p.Author = u.Username
+		}
+	}
+
+	if p.Module == "" {
+		p.Module = p.Keyword
+	}
+
+	return nil
+}
+
+// keywordFromName returns a lower case keyword made of the letters and digits of name.
+func keywordFromName(name string) string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(name) {
+		if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+
+	if b.Len() == 0 {
+		return "workflow"
+	}
+
+	return b.String()
+}
+
+func scaffold(p project) error {
+	if entries, err := os.ReadDir(p.Dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s: %w", p.Dir, errDirNotEmpty)
+	}
+
+	if err := os.MkdirAll(p.Dir, dirPerm); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	funcs := template.FuncMap{"quote": strconv.Quote}
+
+	tmpl, err := template.New("").Funcs(funcs).ParseFS(templateFS, "templates/*.tmpl")
+	if err != nil {
+		return fmt.Errorf("parse templates: %w", err)
+	}
+
+	for _, f := range scaffoldFiles() {
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, f.template, p); err != nil {
+			return fmt.Errorf("render %s: %w", f.path, err)
+		}
+
+		if err := os.WriteFile(filepath.Join(p.Dir, f.path), buf.Bytes(), filePerm); err != nil {
+			return fmt.Errorf("write %s: %w", f.path, err)
+		}
+	}
+
+	if err := p.workflow().WriteFile(filepath.Join(p.Dir, "info.plist")); err != nil {
+		return fmt.Errorf("write info.plist: %w", err)
+	}
+
+	return writeIcon(filepath.Join(p.Dir, "icon.png"))
+}
+
+// workflow returns the info.plist of the scaffold: a Script Filter connected to a Run Script.
+func (p project) workflow() *alfred.Workflow {
+	filter := alfred.NewScriptFilterObject(p.Keyword, `./workflow filter "$1"`).
+		Title(p.Name).
+		Subtext(p.Description).
+		RunningSubtext("Loading...")
+	script := alfred.NewRunScriptObject(`./workflow run "$1"`)
+
+	wf := alfred.NewWorkflow(p.BundleID, p.Name).
+		Version("0.1.0").
+		CreatedBy(p.Author).
+		Description(p.Description).
+		Add(filter, script)
+	wf.Connect(filter, script)
+
+	return wf
+}
+
+// writeIcon writes a placeholder workflow icon: a rounded square with a vertical gradient.
+func writeIcon(path string) error {
+	const radius = 48
+
+	img := shape.RoundedSquare(iconSize, radius, func(x, y int) color.NRGBA {
+		return color.NRGBA{R: 0x3b, G: uint8(0x82 + y/8), B: 0xf6, A: 0xff} //nolint:gomnd // gradient colors
+	})
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create icon: %w", err)
+	}
+
+	if err := png.Encode(f, img); err != nil {
+		_ = f.Close()
+
+		return fmt.Errorf("encode icon: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close icon: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/youwkey/alfred-go"
+)
+
+func TestKeywordFromName(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in  string
+		out string
+	}
+
+	tests := []Test{
+		{in: "Search", out: "search"},
+		{in: "My Workflow 2", out: "myworkflow2"},
+		{in: "go-alfred", out: "goalfred"},
+		{in: "!!!", out: "workflow"},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:keywordFromName", i), func(t *testing.T) {
+			t.Parallel()
+			if got := keywordFromName(test.in); got != test.out {
+				t.Errorf("#%d: got: %s want: %s", i, got, test.out)
+			}
+		})
+	}
+}
+
+func TestRunNew(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "My Search")
+	args := []string{"-keyword", "ms", "-bundleid", "com.example.ms", "-author", "A & B", dir}
+
+	var stdout, stderr bytes.Buffer
+	if err := runNew(args, &stdout, &stderr); err != nil {
+		t.Fatalf("runNew error: %v (stderr: %s)", err, stderr.String())
+	}
+
+	for _, f := range scaffoldFiles() {
+		if _, err := os.Stat(filepath.Join(dir, f.path)); err != nil {
+			t.Errorf("%s not generated: %v", f.path, err)
+		}
+	}
+
+	info, err := alfred.ReadWorkflowInfo(filepath.Join(dir, "info.plist"))
+	if err != nil {
+		t.Fatalf("read info.plist: %v", err)
+	}
+
+	if info.BundleID != "com.example.ms" || info.Name != "My Search" || info.CreatedBy != "A & B" {
+		t.Errorf("info.plist: got: %+v", info)
+	}
+
+	if len(info.Objects) != 2 || info.Objects[0].Config["keyword"] != "ms" {
+		t.Errorf("info.plist objects: got: %+v", info.Objects)
+	}
+
+	for _, name := range []string{"main.go", "main_test.go"} {
+		if _, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, name), nil, parser.AllErrors); err != nil {
+			t.Errorf("%s is not valid Go: %v", name, err)
+		}
+	}
+
+	icon, err := os.Open(filepath.Join(dir, "icon.png"))
+	if err != nil {
+		t.Fatalf("open icon.png: %v", err)
+	}
+	defer icon.Close()
+
+	if _, err := png.Decode(icon); err != nil {
+		t.Errorf("icon.png is not a PNG: %v", err)
+	}
+}
+
+func TestRunNew_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "exists"), nil, filePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	type Test struct {
+		args []string
+		err  error
+	}
+
+	tests := []Test{
+		{args: []string{}, err: errUsage},
+		{args: []string{"a", "b"}, err: errUsage},
+		{args: []string{"-unknown", "a"}, err: errUsage},
+		{args: []string{dir}, err: errDirNotEmpty},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:runNew", i), func(t *testing.T) {
+			t.Parallel()
+			var stdout, stderr bytes.Buffer
+			if err := runNew(test.args, &stdout, &stderr); !errors.Is(err, test.err) {
+				t.Errorf("#%d: got: %v want: %v", i, err, test.err)
+			}
+		})
+	}
+}
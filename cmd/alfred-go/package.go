@@ -0,0 +1,62 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/youwkey/alfred-go"
+)
+
+func runPackage(args []string, stdout, stderr io.Writer) error {
+	var output, ignore string
+
+	fs := flag.NewFlagSet("package", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&output, "o", "", "output directory (default: workflow directory)")
+	fs.StringVar(&ignore, "ignore", alfred.DefaultIgnoreFile, "ignore file, relative to the workflow directory")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: alfred-go package [flags] [dir]")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+
+		return errUsage
+	}
+
+	if fs.NArg() > 1 {
+		fs.Usage()
+
+		return errUsage
+	}
+
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+
+	p := alfred.NewPackager(dir).IgnoreFile(ignore)
+	if output != "" {
+		p.OutputDir(output)
+	}
+
+	result, err := p.Package()
+	if err != nil {
+		return fmt.Errorf("package: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "%s (%d files)\n", result.Path, len(result.Files))
+	fmt.Fprintf(stdout, "sha256 %s\n", result.Checksum)
+
+	return nil
+}
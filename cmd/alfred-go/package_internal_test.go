@@ -0,0 +1,77 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRunPackage(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "wf")
+	out := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	if err := runNew([]string{"-name", "Demo", dir}, &stdout, &stderr); err != nil {
+		t.Fatalf("runNew error: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "workflow"), []byte("binary"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout.Reset()
+
+	if err := runPackage([]string{"-o", out, dir}, &stdout, &stderr); err != nil {
+		t.Fatalf("runPackage error: %v (stderr: %s)", err, stderr.String())
+	}
+
+	archive := filepath.Join(out, "Demo-0.1.0.alfredworkflow")
+	if !strings.Contains(stdout.String(), archive) {
+		t.Errorf("stdout: got: %s want: %s", stdout.String(), archive)
+	}
+
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	defer zr.Close()
+
+	names := make([]string, 0, len(zr.File))
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+
+	sort.Strings(names)
+
+	if want := "icon.png info.plist workflow"; strings.Join(names, " ") != want {
+		t.Errorf("entries: got: %v want: %s", names, want)
+	}
+
+	if _, err := os.Stat(archive + ".sha256"); err != nil {
+		t.Errorf("checksum file not written: %v", err)
+	}
+}
+
+func TestRunPackage_Errors(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	if err := runPackage([]string{"a", "b"}, &stdout, &stderr); !errors.Is(err, errUsage) {
+		t.Errorf("got: %v want: %v", err, errUsage)
+	}
+
+	if err := runPackage([]string{t.TempDir()}, &stdout, &stderr); err == nil {
+		t.Errorf("got no error for a directory without info.plist")
+	}
+}
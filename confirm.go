@@ -0,0 +1,142 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// confirmation defaults.
+const (
+	DefaultConfirmPrefix   = "confirm:"
+	DefaultConfirmVariable = "confirm_pending"
+)
+
+type confirmPending struct {
+	Title string `json:"title"`
+	Arg   string `json:"arg"`
+	Query string `json:"query"`
+}
+
+// Confirm asks for confirmation before actioning destructive items, as Alfred
+// has no confirmation of its own.
+//
+// A wrapped item is invalid and autocompletes to the prefix followed by its
+// key, which reruns the Script Filter. Its arg is kept, with the query to come
+// back to, in a variable of the ScriptFilter (see ScriptFilter.Variables).
+// Handle then renders a "Confirm" item passing the arg through and a "Cancel"
+// item restoring the query.
+type Confirm struct {
+	prefix   string
+	variable string
+	pending  map[string]confirmPending
+}
+
+// NewConfirm returns a Confirm with the default prefix and variable.
+func NewConfirm() *Confirm {
+	return &Confirm{
+		prefix:   DefaultConfirmPrefix,
+		variable: DefaultConfirmVariable,
+		pending:  make(map[string]confirmPending),
+	}
+}
+
+// Prefix sets the prefix of the confirmation queries.
+func (c *Confirm) Prefix(prefix string) *Confirm {
+	c.prefix = prefix
+
+	return c
+}
+
+// Variable sets the name of the variable holding the pending actions.
+func (c *Confirm) Variable(variable string) *Confirm {
+	c.variable = variable
+
+	return c
+}
+
+// Wrap returns a copy of item requiring confirmation, identified by key, and
+// stores its arg in the variables of sf. Query is the current query, restored
+// when the action is cancelled.
+//
+// Modifiers of the copy are removed so they cannot skip the confirmation.
+func (c *Confirm) Wrap(sf *ScriptFilter, query, key string, item *Item) *Item {
+	pending := confirmPending{Title: item.title, Query: query}
+	if item.arg != nil {
+		pending.Arg = *item.arg
+	}
+
+	c.pending[key] = pending
+	c.store(sf)
+
+	wrapped := *item
+	wrapped.arg = nil
+	wrapped.mods = nil
+
+	return wrapped.Valid(false).Autocomplete(c.prefix + key)
+}
+
+// Handle appends to sf the confirmation items when query asks for one, and
+// reports whether it did.
+func (c *Confirm) Handle(query string, sf *ScriptFilter) bool {
+	return c.handle(query, sf, os.LookupEnv)
+}
+
+func (c *Confirm) handle(query string, sf *ScriptFilter, lookup func(string) (string, bool)) bool {
+	if !strings.HasPrefix(query, c.prefix) {
+		return false
+	}
+
+	if v, ok := lookup(c.variable); ok {
+		var pending map[string]confirmPending
+		if err := json.Unmarshal([]byte(v), &pending); err == nil {
+			for key, p := range pending {
+				if _, ok := c.pending[key]; !ok {
+					c.pending[key] = p
+				}
+			}
+		}
+	}
+
+	c.store(sf)
+
+	p, ok := c.pending[strings.TrimPrefix(query, c.prefix)]
+	if !ok {
+		sf.Items().Append(NewInvalidItem("Nothing to confirm").
+			Subtitle("The action has expired, delete the query to start again").
+			Autocomplete("").
+			Icon(IconAlertNote))
+
+		return true
+	}
+
+	sf.Items().Append(
+		NewItem("Confirm: "+p.Title).
+			Subtitle("Press ↩ to proceed").
+			Arg(p.Arg).
+			Icon(IconAlertCautionBadge),
+		NewInvalidItem("Cancel").
+			Subtitle("Go back").
+			Autocomplete(p.Query).
+			Icon(IconAlertStop),
+	)
+
+	return true
+}
+
+func (c *Confirm) store(sf *ScriptFilter) {
+	if len(c.pending) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(c.pending)
+	if err != nil {
+		return
+	}
+
+	sf.Variables().Put(c.variable, string(data))
+}
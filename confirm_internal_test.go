@@ -0,0 +1,101 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestConfirm_Wrap(t *testing.T) {
+	t.Parallel()
+
+	sf := NewScriptFilter()
+	item := NewItem("Delete branch").Arg("feature").ModCmd(NewModifier().Arg("force"))
+
+	got := NewConfirm().Wrap(sf, "git del", "branch-feature", item)
+
+	testMarshalJSON(t, 0, got,
+		`{"title":"Delete branch","valid":false,"autocomplete":"confirm:branch-feature"}`)
+	testMarshalJSON(t, 1, item,
+		`{"title":"Delete branch","arg":"feature","mods":{"cmd":{"arg":"force"}}}`)
+	testMarshalJSON(t, 2, *sf.Variables(),
+		`{"confirm_pending":"{\"branch-feature\":{\"title\":\"Delete branch\",\"arg\":\"feature\",\"query\":\"git del\"}}"}`)
+}
+
+func TestConfirm_Handle(t *testing.T) {
+	t.Parallel()
+
+	pending := `{"branch-feature":{"title":"Delete branch","arg":"feature","query":"git del"}}`
+
+	type Test struct {
+		query string
+		env   map[string]string
+		ok    bool
+		items Items
+	}
+
+	tests := []Test{
+		{query: "git del", env: map[string]string{"confirm_pending": pending}, ok: false, items: Items{}},
+		{
+			query: "confirm:branch-feature",
+			env:   map[string]string{"confirm_pending": pending},
+			ok:    true,
+			items: Items{
+				NewItem("Confirm: Delete branch").Subtitle("Press ↩ to proceed").Arg("feature").Icon(IconAlertCautionBadge),
+				NewInvalidItem("Cancel").Subtitle("Go back").Autocomplete("git del").Icon(IconAlertStop),
+			},
+		},
+		{
+			query: "confirm:other",
+			env:   map[string]string{"confirm_pending": pending},
+			ok:    true,
+			items: Items{
+				NewInvalidItem("Nothing to confirm").
+					Subtitle("The action has expired, delete the query to start again").
+					Autocomplete("").
+					Icon(IconAlertNote),
+			},
+		},
+		{
+			query: "confirm:branch-feature",
+			env:   map[string]string{"confirm_pending": "not json"},
+			ok:    true,
+			items: Items{
+				NewInvalidItem("Nothing to confirm").
+					Subtitle("The action has expired, delete the query to start again").
+					Autocomplete("").
+					Icon(IconAlertNote),
+			},
+		},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Handle", i), func(t *testing.T) {
+			t.Parallel()
+
+			sf := NewScriptFilter()
+			if ok := NewConfirm().handle(test.query, sf, lookupMap(test.env)); ok != test.ok {
+				t.Errorf("#%d: got: %v want: %v", i, ok, test.ok)
+			}
+
+			testMarshalJSON(t, i, *sf.Items(), testItemsJSON(t, test.items))
+		})
+	}
+}
+
+func TestConfirm_HandleKeepsPending(t *testing.T) {
+	t.Parallel()
+
+	pending := `{"a":{"title":"A","arg":"1","query":""}}`
+
+	sf := NewScriptFilter()
+	NewConfirm().Prefix("sure?").Variable("pending").handle("sure?a", sf, lookupMap(map[string]string{"pending": pending}))
+
+	if got := (*sf.Variables())["pending"]; got != pending {
+		t.Errorf("got: %v want: %v", got, pending)
+	}
+}
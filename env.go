@@ -0,0 +1,67 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrNoWorkflowDir is returned when the cache or data directory is needed outside Alfred.
+var ErrNoWorkflowDir = errors.New("workflow directory is not set")
+
+// Environment variables set by Alfred when it runs a workflow script.
+const (
+	EnvAlfredVersion      = "alfred_version"
+	EnvAlfredVersionBuild = "alfred_version_build"
+	EnvThemeBackground    = "alfred_theme_background"
+	EnvDebug              = "alfred_debug"
+	EnvWorkflowBundleID   = "alfred_workflow_bundleid"
+	EnvWorkflowName       = "alfred_workflow_name"
+	EnvWorkflowVersion    = "alfred_workflow_version"
+	EnvWorkflowUID        = "alfred_workflow_uid"
+	EnvWorkflowCache      = "alfred_workflow_cache"
+	EnvWorkflowData       = "alfred_workflow_data"
+)
+
+// BundleID returns the bundle ID of the running workflow.
+func BundleID() string {
+	return os.Getenv(EnvWorkflowBundleID)
+}
+
+// WorkflowVersion returns the version of the running workflow.
+func WorkflowVersion() string {
+	return os.Getenv(EnvWorkflowVersion)
+}
+
+// CacheDir returns the cache directory of the running workflow, or "" outside Alfred.
+//
+// The directory is not created by Alfred; use EnsureDir before writing to it.
+func CacheDir() string {
+	return os.Getenv(EnvWorkflowCache)
+}
+
+// DataDir returns the data directory of the running workflow, or "" outside Alfred.
+//
+// The directory is not created by Alfred; use EnsureDir before writing to it.
+func DataDir() string {
+	return os.Getenv(EnvWorkflowData)
+}
+
+// IsDebug reports whether the workflow runs with Alfred's debugger open.
+func IsDebug() bool {
+	return os.Getenv(EnvDebug) == "1"
+}
+
+// EnsureDir creates dir and its parents if they do not exist and returns dir.
+func EnsureDir(dir string) (string, error) {
+	const perm = 0o700
+
+	if err := os.MkdirAll(dir, perm); err != nil {
+		return "", err //nolint:wrapcheck // os errors include the path
+	}
+
+	return dir, nil
+}
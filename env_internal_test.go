@@ -0,0 +1,66 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnv(t *testing.T) {
+	t.Setenv(EnvWorkflowBundleID, "com.example.test")
+	t.Setenv(EnvWorkflowVersion, "1.0.0")
+	t.Setenv(EnvWorkflowCache, "/cache")
+	t.Setenv(EnvWorkflowData, "/data")
+	t.Setenv(EnvDebug, "1")
+
+	type Test struct {
+		got  string
+		want string
+	}
+
+	tests := []Test{
+		{got: BundleID(), want: "com.example.test"},
+		{got: WorkflowVersion(), want: "1.0.0"},
+		{got: CacheDir(), want: "/cache"},
+		{got: DataDir(), want: "/data"},
+	}
+
+	for i, test := range tests {
+		if test.got != test.want {
+			t.Errorf("#%d: got: %s want: %s", i, test.got, test.want)
+		}
+	}
+
+	if !IsDebug() {
+		t.Errorf("IsDebug: got: false want: true")
+	}
+
+	t.Setenv(EnvDebug, "0")
+
+	if IsDebug() {
+		t.Errorf("IsDebug: got: true want: false")
+	}
+}
+
+func TestEnsureDir(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "a", "b")
+
+	got, err := EnsureDir(dir)
+	if err != nil || got != dir {
+		t.Fatalf("got: %s, %v want: %s", got, err, dir)
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("directory not created: %v", err)
+	}
+
+	if _, err := EnsureDir(dir); err != nil {
+		t.Errorf("existing directory: got: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// EscapeFlags is the set of escaping options of Run Script and Script Filter objects.
+type EscapeFlags int
+
+// escape flags, with the values Alfred stores in info.plist.
+const (
+	EscapeSpaces       EscapeFlags = 1
+	EscapeBackquotes   EscapeFlags = 2
+	EscapeDoubleQuotes EscapeFlags = 4
+	EscapeBrackets     EscapeFlags = 8
+	EscapeSemicolons   EscapeFlags = 16
+	EscapeDollars      EscapeFlags = 32
+	EscapeBackslashes  EscapeFlags = 64
+
+	// EscapeBashDefault is the escaping Alfred selects for new bash scripts.
+	EscapeBashDefault = EscapeBackquotes | EscapeDoubleQuotes | EscapeDollars | EscapeBackslashes
+)
+
+// SafeArgPrefix starts the args encoded by EncodeSafeArg.
+const SafeArgPrefix = "safearg:"
+
+// ErrNotSafeArg is returned when decoding an arg not encoded by EncodeSafeArg.
+var ErrNotSafeArg = errors.New("not a safe arg")
+
+// chars returns the characters escaped with the flags.
+func (f EscapeFlags) chars() string {
+	var b strings.Builder
+
+	for _, e := range []struct {
+		flag  EscapeFlags
+		chars string
+	}{
+		{flag: EscapeSpaces, chars: " "},
+		{flag: EscapeBackquotes, chars: "`"},
+		{flag: EscapeDoubleQuotes, chars: `"`},
+		{flag: EscapeBrackets, chars: "()[]{}"},
+		{flag: EscapeSemicolons, chars: ";"},
+		{flag: EscapeDollars, chars: "$"},
+		{flag: EscapeBackslashes, chars: `\`},
+	} {
+		if f&e.flag != 0 {
+			b.WriteString(e.chars)
+		}
+	}
+
+	return b.String()
+}
+
+// Escape escapes s the way Alfred does with the flags, by prefixing each
+// escaped character with a backslash.
+func Escape(s string, flags EscapeFlags) string {
+	chars := flags.chars()
+
+	var b strings.Builder
+
+	for _, r := range s {
+		if strings.ContainsRune(chars, r) {
+			b.WriteByte('\\')
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// Unescape reverses Escape with the same flags.
+func Unescape(s string, flags EscapeFlags) string {
+	chars := flags.chars()
+	runes := []rune(s)
+
+	var b strings.Builder
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune(chars, runes[i+1]) {
+			i++
+		}
+
+		b.WriteRune(runes[i])
+	}
+
+	return b.String()
+}
+
+// EncodeSafeArg encodes v as JSON in URL safe base64, so the arg passes any
+// escaping and shell unchanged. The receiving program decodes it with DecodeSafeArg.
+func EncodeSafeArg(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("safe arg: %w", err)
+	}
+
+	return SafeArgPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeSafeArg decodes an arg encoded by EncodeSafeArg into v.
+func DecodeSafeArg(arg string, v interface{}) error {
+	arg = strings.TrimSpace(arg)
+	if !strings.HasPrefix(arg, SafeArgPrefix) {
+		return ErrNotSafeArg
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(arg, SafeArgPrefix))
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNotSafeArg, err.Error())
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("%w: %s", ErrNotSafeArg, err.Error())
+	}
+
+	return nil
+}
+
+// SafeArgString decodes a string encoded by EncodeSafeArg, and returns any
+// other arg as is.
+func SafeArgString(arg string) string {
+	var s string
+	if err := DecodeSafeArg(arg, &s); err != nil {
+		return arg
+	}
+
+	return s
+}
+
+func safeArg(s string) string {
+	arg, _ := EncodeSafeArg(s) // strings always encode
+
+	return arg
+}
+
+// SafeArg sets the arg to arg encoded by EncodeSafeArg.
+func (i *Item) SafeArg(arg string) *Item {
+	return i.Arg(safeArg(arg))
+}
+
+// SafeArg sets the arg to arg encoded by EncodeSafeArg.
+func (m *Modifier) SafeArg(arg string) *Modifier {
+	return m.Arg(safeArg(arg))
+}
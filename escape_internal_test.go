@@ -0,0 +1,109 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestEscape(t *testing.T) {
+	t.Parallel()
+
+	const in = "a b`c\"d(e)[f]{g};h$i\\j'k"
+
+	type Test struct {
+		flags EscapeFlags
+		out   string
+	}
+
+	tests := []Test{
+		{flags: 0, out: in},
+		{flags: EscapeSpaces, out: "a\\ b`c\"d(e)[f]{g};h$i\\j'k"},
+		{flags: EscapeBackquotes, out: "a b\\`c\"d(e)[f]{g};h$i\\j'k"},
+		{flags: EscapeDoubleQuotes, out: "a b`c\\\"d(e)[f]{g};h$i\\j'k"},
+		{flags: EscapeBrackets, out: "a b`c\"d\\(e\\)\\[f\\]\\{g\\};h$i\\j'k"},
+		{flags: EscapeSemicolons, out: "a b`c\"d(e)[f]{g}\\;h$i\\j'k"},
+		{flags: EscapeDollars, out: "a b`c\"d(e)[f]{g};h\\$i\\j'k"},
+		{flags: EscapeBackslashes, out: "a b`c\"d(e)[f]{g};h$i\\\\j'k"},
+		{flags: EscapeBashDefault, out: "a b\\`c\\\"d(e)[f]{g};h\\$i\\\\j'k"},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Escape", i), func(t *testing.T) {
+			t.Parallel()
+			got := Escape(in, test.flags)
+			if got != test.out {
+				t.Errorf("#%d: got: %s want: %s", i, got, test.out)
+			}
+			if back := Unescape(got, test.flags); back != in {
+				t.Errorf("#%d: unescape: got: %s want: %s", i, back, in)
+			}
+		})
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	t.Parallel()
+
+	// Backslashes not followed by an escaped character are kept.
+	if got, want := Unescape(`\$a\b\`, EscapeDollars), `$a\b\`; got != want {
+		t.Errorf("got: %s want: %s", got, want)
+	}
+}
+
+func TestSafeArg(t *testing.T) {
+	t.Parallel()
+
+	type payload struct {
+		Cmd  string   `json:"cmd"`
+		Args []string `json:"args"`
+	}
+
+	in := payload{Cmd: "open", Args: []string{`"; rm -rf $HOME`, "`id`", "a b"}}
+
+	arg, err := EncodeSafeArg(in)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+
+	if Escape(arg, EscapeSpaces|EscapeBackquotes|EscapeDoubleQuotes|EscapeBrackets|
+		EscapeSemicolons|EscapeDollars|EscapeBackslashes) != arg {
+		t.Errorf("got: %s escaped by Alfred", arg)
+	}
+
+	var out payload
+	if err := DecodeSafeArg(arg+"\n", &out); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got: %+v want: %+v", out, in)
+	}
+
+	for i, bad := range []string{"plain", SafeArgPrefix + "!!", SafeArgPrefix + "bm90IGpzb24"} {
+		if err := DecodeSafeArg(bad, &out); !errors.Is(err, ErrNotSafeArg) {
+			t.Errorf("#%d: got: %v want: %v", i, err, ErrNotSafeArg)
+		}
+	}
+}
+
+func TestSafeArgString(t *testing.T) {
+	t.Parallel()
+
+	if got := SafeArgString(safeArg("$(id)")); got != "$(id)" {
+		t.Errorf("got: %s want: $(id)", got)
+	}
+
+	if got := SafeArgString("plain"); got != "plain" {
+		t.Errorf("got: %s want: plain", got)
+	}
+
+	testMarshalJSON(t, 0, NewItem("title").SafeArg("a b"), `{"title":"title","arg":"safearg:ImEgYiI"}`)
+	testMarshalJSON(t, 1, NewModifier().SafeArg("a b"), `{"arg":"safearg:ImEgYiI"}`)
+}
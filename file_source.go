@@ -0,0 +1,229 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileTimeLayout is the layout of the modified time in the subtitles of file items.
+const FileTimeLayout = "2006-01-02 15:04"
+
+// errLimitReached stops walking once the item limit is reached.
+var errLimitReached = errors.New("limit reached")
+
+// FileSource walks a directory and makes an Item of each file found, for
+// workflows browsing folders.
+type FileSource struct {
+	root     string
+	match    []string
+	ignore   []string
+	maxDepth int
+	hidden   bool
+	dirs     bool
+	limit    int
+}
+
+// NewFileSource returns a FileSource listing the direct children of root,
+// except hidden files.
+func NewFileSource(root string) *FileSource {
+	return &FileSource{root: root, maxDepth: 1, dirs: true}
+}
+
+// Match sets glob patterns, as understood by filepath.Match, the names of the
+// files must match. Directories are walked whatever their name.
+func (s *FileSource) Match(patterns ...string) *FileSource {
+	s.match = patterns
+
+	return s
+}
+
+// Ignore sets glob patterns of files and directories to leave out, matched
+// against both their name and their slash separated path relative to the root.
+// Ignored directories are not walked.
+func (s *FileSource) Ignore(patterns ...string) *FileSource {
+	s.ignore = patterns
+
+	return s
+}
+
+// MaxDepth sets how deep the root is walked: 1 lists its children only and 0
+// walks it entirely.
+func (s *FileSource) MaxDepth(depth int) *FileSource {
+	s.maxDepth = depth
+
+	return s
+}
+
+// Hidden sets whether files and directories whose name starts with a dot are listed and walked.
+func (s *FileSource) Hidden(hidden bool) *FileSource {
+	s.hidden = hidden
+
+	return s
+}
+
+// Dirs sets whether directories are listed as items too.
+func (s *FileSource) Dirs(dirs bool) *FileSource {
+	s.dirs = dirs
+
+	return s
+}
+
+// Limit sets the maximum number of items, 0 for no limit.
+func (s *FileSource) Limit(limit int) *FileSource {
+	s.limit = limit
+
+	return s
+}
+
+// Items walks the root in lexical order and returns an Item of each file found.
+//
+// Items are of type ItemTypeFile with the path as uid, arg and Quick Look URL,
+// the file icon, and a subtitle made of the path, abbreviated with a tilde for
+// the home directory, and the modified time. Paths are absolute, even for a
+// relative root, with a symlinked root resolved. Entries which cannot be read are skipped; only an unreadable
+// root is an error.
+func (s *FileSource) Items() (Items, error) {
+	for _, p := range append(append([]string(nil), s.match...), s.ignore...) {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("%w: %q", err, p)
+		}
+	}
+
+	root, err := filepath.Abs(s.root)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // filepath errors include the path
+	}
+
+	// WalkDir does not follow a symlinked root, such as a linked Dropbox folder.
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return nil, err //nolint:wrapcheck // filepath errors include the path
+	}
+
+	var items Items
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if path == root {
+			return err
+		}
+
+		if err != nil {
+			return skipEntry(d)
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err //nolint:wrapcheck // filepath errors include the path
+		}
+
+		if s.skip(d.Name(), filepath.ToSlash(rel)) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if (d.IsDir() && s.dirs) || (!d.IsDir() && s.matches(d.Name())) {
+			info, err := d.Info()
+			if err != nil {
+				return skipEntry(d)
+			}
+
+			items = append(items, FileItem(path, info))
+
+			if s.limit > 0 && len(items) == s.limit {
+				return errLimitReached
+			}
+		}
+
+		if d.IsDir() && s.maxDepth > 0 && strings.Count(filepath.ToSlash(rel), "/")+1 >= s.maxDepth {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errLimitReached) {
+		return nil, err //nolint:wrapcheck // fs errors include the path
+	}
+
+	return items, nil
+}
+
+// skipEntry returns the error which makes WalkDir skip the entry d.
+func skipEntry(d fs.DirEntry) error {
+	if d != nil && d.IsDir() {
+		return filepath.SkipDir
+	}
+
+	return nil
+}
+
+func (s *FileSource) skip(name, rel string) bool {
+	if !s.hidden && strings.HasPrefix(name, ".") {
+		return true
+	}
+
+	for _, p := range s.ignore {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+
+		if ok, _ := filepath.Match(p, rel); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *FileSource) matches(name string) bool {
+	if len(s.match) == 0 {
+		return true
+	}
+
+	for _, p := range s.match {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FileItem returns an Item of type ItemTypeFile for the file at path.
+func FileItem(path string, info fs.FileInfo) *Item {
+	return NewItem(info.Name()).
+		UID(path).
+		Arg(path).
+		Type(ItemTypeFile).
+		QuicklookURL(path).
+		Subtitle(AbbreviateHome(path) + " · " + info.ModTime().Format(FileTimeLayout)).
+		Icon(NewIconWithType(path, IconTypeFileIcon))
+}
+
+// AbbreviateHome returns path with the home directory replaced by a tilde.
+func AbbreviateHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+
+	home = filepath.Clean(home)
+
+	switch {
+	case path == home:
+		return "~"
+	case strings.HasPrefix(path, home+string(filepath.Separator)):
+		return "~" + path[len(home):]
+	default:
+		return path
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testFileSourceTree(t *testing.T) string {
+	t.Helper()
+
+	// Items resolves symlinks, such as /var on macOS.
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestFiles(t, dir, map[string]string{
+		"a.txt":              "a",
+		"b.pdf":              "b",
+		".hidden":            "h",
+		"docs/c.txt":         "c",
+		"docs/deep/d.txt":    "d",
+		"node_modules/e.txt": "e",
+		".git/config":        "g",
+	})
+
+	return dir
+}
+
+func testFilePaths(t *testing.T, root string, items Items) []string {
+	t.Helper()
+
+	paths := make([]string, 0, len(items))
+
+	for _, item := range items {
+		rel, err := filepath.Rel(root, *item.uid)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+
+	return paths
+}
+
+func TestFileSource_Items(t *testing.T) {
+	t.Parallel()
+
+	root := testFileSourceTree(t)
+
+	type Test struct {
+		in  *FileSource
+		out []string
+	}
+
+	tests := []Test{
+		{in: NewFileSource(root), out: []string{"a.txt", "b.pdf", "docs", "node_modules"}},
+		{in: NewFileSource(root).Dirs(false), out: []string{"a.txt", "b.pdf"}},
+		{in: NewFileSource(root).Hidden(true), out: []string{".git", ".hidden", "a.txt", "b.pdf", "docs", "node_modules"}},
+		{
+			in:  NewFileSource(root).MaxDepth(2),
+			out: []string{"a.txt", "b.pdf", "docs", "docs/c.txt", "docs/deep", "node_modules", "node_modules/e.txt"},
+		},
+		{
+			in:  NewFileSource(root).MaxDepth(0).Dirs(false).Ignore("node_modules"),
+			out: []string{"a.txt", "b.pdf", "docs/c.txt", "docs/deep/d.txt"},
+		},
+		{
+			in:  NewFileSource(root).MaxDepth(0).Dirs(false).Ignore("docs/deep", "b.*"),
+			out: []string{"a.txt", "docs/c.txt", "node_modules/e.txt"},
+		},
+		{
+			in:  NewFileSource(root).MaxDepth(0).Dirs(false).Match("*.txt"),
+			out: []string{"a.txt", "docs/c.txt", "docs/deep/d.txt", "node_modules/e.txt"},
+		},
+		{in: NewFileSource(root).MaxDepth(0).Dirs(false).Limit(2), out: []string{"a.txt", "b.pdf"}},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Items", i), func(t *testing.T) {
+			t.Parallel()
+
+			items, err := test.in.Items()
+			if err != nil {
+				t.Fatalf("#%d: error: %v", i, err)
+			}
+
+			if got := testFilePaths(t, root, items); !reflect.DeepEqual(got, test.out) {
+				t.Errorf("#%d: got: %q want: %q", i, got, test.out)
+			}
+		})
+	}
+}
+
+func TestFileSource_ItemsError(t *testing.T) {
+	t.Parallel()
+
+	root := testFileSourceTree(t)
+
+	if _, err := NewFileSource(root).Match("[").Items(); !errors.Is(err, filepath.ErrBadPattern) {
+		t.Errorf("got: %v want: %v", err, filepath.ErrBadPattern)
+	}
+
+	if _, err := NewFileSource(filepath.Join(root, "missing")).Items(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got: %v want: %v", err, os.ErrNotExist)
+	}
+}
+
+func TestFileSource_ItemsRelative(t *testing.T) {
+	t.Parallel()
+
+	root := testFileSourceTree(t)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rel, err := filepath.Rel(wd, root)
+	if err != nil {
+		t.Skip(err)
+	}
+
+	items, err := NewFileSource(rel).Dirs(false).Match("*.txt").Items()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := filepath.Join(root, "a.txt"); len(items) != 1 || *items[0].uid != want {
+		t.Errorf("got: %q want: %q", testFilePaths(t, root, items), want)
+	}
+}
+
+func TestFileSource_ItemsSymlink(t *testing.T) {
+	t.Parallel()
+
+	root := testFileSourceTree(t)
+	link := filepath.Join(t.TempDir(), "link")
+
+	if err := os.Symlink(root, link); err != nil {
+		t.Skip(err)
+	}
+
+	items, err := NewFileSource(link).Items()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"a.txt", "b.pdf", "docs", "node_modules"}
+	if got := testFilePaths(t, root, items); !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %q want: %q", got, want)
+	}
+}
+
+func TestFileSource_ItemsUnreadable(t *testing.T) {
+	t.Parallel()
+
+	root := testFileSourceTree(t)
+	locked := filepath.Join(root, "docs", "deep")
+
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(locked, 0o755) //nolint:errcheck
+
+	if _, err := os.ReadDir(locked); err == nil {
+		t.Skip("directory is readable despite its mode")
+	}
+
+	items, err := NewFileSource(root).MaxDepth(0).Dirs(false).Match("*.txt").Items()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"a.txt", "docs/c.txt", "node_modules/e.txt"}
+	if got := testFilePaths(t, root, items); !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %q want: %q", got, want)
+	}
+}
+
+func TestFileItem(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path := filepath.Join(home, "report.pdf")
+	writeTestFiles(t, home, map[string]string{"report.pdf": "pdf"})
+
+	modified := time.Date(2021, 3, 4, 15, 4, 0, 0, time.Local)
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := NewItem("report.pdf").
+		UID(path).
+		Arg(path).
+		Type(ItemTypeFile).
+		QuicklookURL(path).
+		Subtitle("~/report.pdf · 2021-03-04 15:04").
+		Icon(NewIconWithType(path, IconTypeFileIcon))
+
+	if got := FileItem(path, info); !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %+v want: %+v", got, want)
+	}
+}
+
+func TestAbbreviateHome(t *testing.T) {
+	t.Setenv("HOME", "/Users/alfred")
+
+	type Test struct {
+		in  string
+		out string
+	}
+
+	tests := []Test{
+		{in: "/Users/alfred", out: "~"},
+		{in: "/Users/alfred/Documents/a.txt", out: "~/Documents/a.txt"},
+		{in: "/Users/alfredo/a.txt", out: "/Users/alfredo/a.txt"},
+		{in: "/tmp/a.txt", out: "/tmp/a.txt"},
+	}
+
+	for i, test := range tests {
+		if got := AbbreviateHome(test.in); got != test.out {
+			t.Errorf("#%d: got: %q want: %q", i, got, test.out)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Group is a section of results, such as "Recent" or "Favorites", shown
+// below a header item as Alfred has no sections of its own.
+type Group struct {
+	title string
+	icon  *Icon
+	items Items
+}
+
+// NewGroup returns a Group with the given title and the default header icon.
+func NewGroup(title string) *Group {
+	return &Group{title: title, icon: IconToolbarLabels}
+}
+
+// Icon sets the icon of the header.
+func (g *Group) Icon(icon *Icon) *Group {
+	g.icon = icon
+
+	return g
+}
+
+// Add appends items to the Group.
+func (g *Group) Add(items ...*Item) *Group {
+	g.items = append(g.items, items...)
+
+	return g
+}
+
+// AppendGroups appends the groups in order, each one below a header item.
+//
+// Items of a group are filtered with FuzzyMatch against query, on their match
+// field or else their title, and sorted by score, keeping their order on equal
+// scores. Groups without matching items have no header. Headers are invalid
+// items without autocomplete, so actioning them does nothing.
+//
+// Alfred reorders items it has learned about, which mixes the groups; set
+// SkipKnowledge on the ScriptFilter to keep them.
+func (i *Items) AppendGroups(query string, groups ...*Group) {
+	for _, g := range groups {
+		matched := g.filter(query)
+		if len(matched) == 0 {
+			continue
+		}
+
+		count := fmt.Sprintf("%d items", len(matched))
+		if len(matched) == 1 {
+			count = "1 item"
+		}
+
+		i.Append(NewInvalidItem(g.title).Subtitle(count).Icon(g.icon))
+		i.Append(matched...)
+	}
+}
+
+func (g *Group) filter(query string) Items {
+	type scored struct {
+		item  *Item
+		score int
+	}
+
+	var matches []scored
+
+	for _, item := range g.items {
+		text := item.title
+		if item.match != nil {
+			text = *item.match
+		}
+
+		if score, ok := FuzzyMatch(query, text); ok {
+			matches = append(matches, scored{item: item, score: score})
+		}
+	}
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		return matches[a].score > matches[b].score
+	})
+
+	items := make(Items, len(matches))
+	for n, m := range matches {
+		items[n] = m.item
+	}
+
+	return items
+}
+
+// FuzzyMatch reports whether the characters of query appear in text in order,
+// ignoring case and spaces, and returns a score which is higher for closer
+// matches. Consecutive characters and characters starting a word score more,
+// and the best scoring alignment is used. An empty query matches any text
+// with a score of 0.
+func FuzzyMatch(query, text string) (int, bool) {
+	const (
+		matchScore       = 1
+		consecutiveBonus = 8
+		wordStartBonus   = 6
+		none             = -1
+	)
+
+	q := []rune(strings.Join(strings.Fields(query), ""))
+	for n := range q {
+		q[n] = unicode.ToLower(q[n])
+	}
+
+	orig := []rune(text)
+	t := make([]rune, len(orig))
+
+	for n, r := range orig {
+		t[n] = unicode.ToLower(r)
+	}
+
+	if len(q) == 0 {
+		return 0, true
+	}
+
+	// prev[ti] is the best score of the query so far with its last character at ti.
+	prev := make([]int, len(t))
+	for ti := range t {
+		prev[ti] = none
+		if t[ti] == q[0] {
+			prev[ti] = matchScore + wordStart(orig, ti)*wordStartBonus
+		}
+	}
+
+	for qi := 1; qi < len(q); qi++ {
+		cur := make([]int, len(t))
+		best := none // best score at positions before ti-1
+
+		for ti := range t {
+			cur[ti] = none
+
+			if ti >= 2 && prev[ti-2] > best {
+				best = prev[ti-2]
+			}
+
+			if t[ti] != q[qi] {
+				continue
+			}
+
+			from := best
+			if ti >= 1 && prev[ti-1] != none && prev[ti-1]+consecutiveBonus > from {
+				from = prev[ti-1] + consecutiveBonus
+			}
+
+			if from != none {
+				cur[ti] = from + matchScore + wordStart(orig, ti)*wordStartBonus
+			}
+		}
+
+		prev = cur
+	}
+
+	score := none
+	for _, s := range prev {
+		if s > score {
+			score = s
+		}
+	}
+
+	return score, score != none
+}
+
+// wordStart returns 1 when the rune at i starts a word, else 0.
+func wordStart(text []rune, i int) int {
+	if i == 0 {
+		return 1
+	}
+
+	r, before := text[i], text[i-1]
+
+	if !unicode.IsLetter(before) && !unicode.IsDigit(before) {
+		return 1
+	}
+
+	if unicode.IsUpper(r) && unicode.IsLower(before) {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		query string
+		text  string
+		ok    bool
+	}
+
+	tests := []Test{
+		{query: "", text: "anything", ok: true},
+		{query: "gh", text: "GitHub", ok: true},
+		{query: "git hub", text: "GitHub", ok: true},
+		{query: "GITHUB", text: "github", ok: true},
+		{query: "hg", text: "GitHub", ok: false},
+		{query: "gitlab", text: "GitHub", ok: false},
+		{query: "é", text: "Élan", ok: true},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:FuzzyMatch", i), func(t *testing.T) {
+			t.Parallel()
+
+			if _, ok := FuzzyMatch(test.query, test.text); ok != test.ok {
+				t.Errorf("#%d: got: %v want: %v", i, ok, test.ok)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatch_Score(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		query  string
+		better string
+		worse  string
+	}
+
+	tests := []Test{
+		{query: "rep", better: "report", worse: "rename pages"},
+		{query: "gh", better: "GitHub", worse: "lighthouse"},
+		{query: "wf", better: "my workflow files", worse: "wolf"},
+	}
+
+	for i, test := range tests {
+		better, _ := FuzzyMatch(test.query, test.better)
+		worse, _ := FuzzyMatch(test.query, test.worse)
+
+		if better <= worse {
+			t.Errorf("#%d: got: %d <= %d want: %q above %q", i, better, worse, test.better, test.worse)
+		}
+	}
+}
+
+func TestItems_AppendGroups(t *testing.T) {
+	t.Parallel()
+
+	groups := func() []*Group {
+		return []*Group{
+			NewGroup("Recent").Add(NewItem("report.pdf"), NewItem("notes.txt")),
+			NewGroup("Favorites").Icon(IconFavoriteItems).Add(NewItem("photos")),
+			NewGroup("Empty"),
+			NewGroup("All").Add(
+				NewItem("rename pages"),
+				NewItem("report.pdf"),
+				NewItem("alias").Match("report archive"),
+			),
+		}
+	}
+
+	type Test struct {
+		query  string
+		titles []string
+	}
+
+	tests := []Test{
+		{
+			query:  "",
+			titles: []string{"Recent", "report.pdf", "notes.txt", "Favorites", "photos", "All", "rename pages", "report.pdf", "alias"},
+		},
+		{query: "rep", titles: []string{"Recent", "report.pdf", "All", "report.pdf", "alias", "rename pages"}},
+		{query: "pho", titles: []string{"Favorites", "photos"}},
+		{query: "zzz", titles: []string{}},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:AppendGroups", i), func(t *testing.T) {
+			t.Parallel()
+
+			items := Items{}
+			items.AppendGroups(test.query, groups()...)
+
+			if titles := testItemTitles(t, items); !reflect.DeepEqual(titles, test.titles) {
+				t.Errorf("#%d: got: %q want: %q", i, titles, test.titles)
+			}
+		})
+	}
+
+	items := Items{}
+	items.AppendGroups("pho", groups()...)
+
+	want := Items{
+		NewInvalidItem("Favorites").Subtitle("1 item").Icon(IconFavoriteItems),
+		NewItem("photos"),
+	}
+	testMarshalJSON(t, 0, items, testItemsJSON(t, want))
+
+	items = Items{}
+	items.AppendGroups("", NewGroup("Recent").Add(NewItem("a"), NewItem("b")))
+	testMarshalJSON(t, 1, items[0], `{"title":"Recent","subtitle":"2 items",`+
+		`"icon":{"path":"/System/Library/CoreServices/CoreTypes.bundle/Contents/Resources/ToolbarLabels.icns"},"valid":false}`)
+}
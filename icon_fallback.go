@@ -0,0 +1,157 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// iconStats caches whether icon files exist, so each path is checked once
+// however many items use it.
+//
+//nolint:gochecknoglobals // cache shared by all icons
+var iconStats = struct {
+	sync.Mutex
+	exists map[string]bool
+}{
+	exists: make(map[string]bool),
+}
+
+// Fallback returns a copy of the Icon using the given icons, in order, when
+// its file does not exist, skipping nil icons. A system icon such as
+// IconGenericDocument makes a good last resort.
+//
+// Relative paths are checked from the working directory, which is the workflow
+// folder when run by Alfred. Icons of type IconTypeFileType name a UTI rather
+// than a file and always exist. When no icon of the chain exists, the last one
+// is used.
+func (i *Icon) Fallback(icons ...*Icon) *Icon {
+	c := *i
+	c.fallbacks = append([]*Icon(nil), i.fallbacks...)
+
+	for _, icon := range icons {
+		if icon != nil {
+			c.fallbacks = append(c.fallbacks, icon)
+		}
+	}
+
+	return &c
+}
+
+// chain returns the Icon followed by its fallbacks and theirs.
+func (i *Icon) chain() []*Icon {
+	chain := []*Icon{i}
+	for _, f := range i.fallbacks {
+		chain = append(chain, f.chain()...)
+	}
+
+	return chain
+}
+
+// available returns the first icon of the chain whose file exists for theme.
+func (i *Icon) available(theme Theme) *Icon {
+	if len(i.fallbacks) == 0 {
+		return i
+	}
+
+	chain := i.chain()
+	for _, c := range chain {
+		if c.exists(theme) {
+			return c
+		}
+	}
+
+	return chain[len(chain)-1]
+}
+
+func (i *Icon) exists(theme Theme) bool {
+	if i.typ != nil && *i.typ == IconTypeFileType {
+		return true
+	}
+
+	return fileExists(i.resolve(theme))
+}
+
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+
+	iconStats.Lock()
+	defer iconStats.Unlock()
+
+	exists, ok := iconStats.exists[path]
+	if !ok {
+		_, err := os.Stat(path)
+		exists = err == nil
+		iconStats.exists[path] = exists
+	}
+
+	return exists
+}
+
+// MissingIcons describes the icons of the items and their modifiers whose
+// files do not exist, with the fallback used instead if any.
+//
+// In debug mode, the missing icons are also reported on stderr when the
+// ScriptFilter is output.
+func (sf *ScriptFilter) MissingIcons() []string {
+	theme := CurrentTheme()
+
+	var missing []string
+
+	report := func(icon *Icon, owner string) {
+		if icon == nil {
+			return
+		}
+
+		used := icon.available(theme)
+
+		for _, c := range icon.chain() {
+			if c.exists(theme) {
+				break
+			}
+
+			msg := fmt.Sprintf("icon %q of %s not found", c.resolve(theme), owner)
+			if c != used {
+				msg += fmt.Sprintf(", using %q", used.resolve(theme))
+			}
+
+			missing = append(missing, msg)
+		}
+	}
+
+	for _, item := range sf.items {
+		if item == nil {
+			continue
+		}
+
+		owner := fmt.Sprintf("item %q", item.title)
+		report(item.icon, owner)
+
+		if item.mods == nil {
+			continue
+		}
+
+		for _, m := range []struct {
+			key string
+			mod *Modifier
+		}{
+			{key: "shift", mod: item.mods.shift},
+			{key: "fn", mod: item.mods.fn},
+			{key: "ctrl", mod: item.mods.ctrl},
+			{key: "alt", mod: item.mods.alt},
+			{key: "cmd", mod: item.mods.cmd},
+		} {
+			if m.mod != nil {
+				report(m.mod.icon, fmt.Sprintf("%s modifier of %s", m.key, owner))
+			}
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIcon_Fallback(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"icon.png": "png", "alt.png": "png"})
+
+	icon := filepath.Join(dir, "icon.png")
+	alt := filepath.Join(dir, "alt.png")
+	missing := filepath.Join(dir, "missing.png")
+	typo := filepath.Join(dir, "typo.png")
+
+	type Test struct {
+		in  *Icon
+		out string
+	}
+
+	tests := []Test{
+		{in: NewIcon(missing), out: fmt.Sprintf(`{"path":%q}`, missing)},
+		{in: NewIcon(icon).Fallback(NewIcon(alt)), out: fmt.Sprintf(`{"path":%q}`, icon)},
+		{in: NewIcon(missing).Fallback(NewIcon(alt)), out: fmt.Sprintf(`{"path":%q}`, alt)},
+		{in: NewIcon(missing).Fallback(NewIcon(typo), NewIcon(alt)), out: fmt.Sprintf(`{"path":%q}`, alt)},
+		{in: NewIcon(missing).Fallback(NewIcon(typo).Fallback(NewIcon(alt))), out: fmt.Sprintf(`{"path":%q}`, alt)},
+		{in: NewIcon(missing).Fallback(NewIcon(typo)), out: fmt.Sprintf(`{"path":%q}`, typo)},
+		{in: NewIcon(missing).Fallback(nil), out: fmt.Sprintf(`{"path":%q}`, missing)},
+		{in: NewIcon(missing).Fallback(nil, NewIcon(alt), nil), out: fmt.Sprintf(`{"path":%q}`, alt)},
+		{
+			in:  NewIcon(missing).Fallback(UTIPDF.Icon()),
+			out: `{"path":"com.adobe.pdf","type":"filetype"}`,
+		},
+		{
+			in:  NewIconWithType(missing, IconTypeFileIcon).Fallback(NewIconWithType(alt, IconTypeFileIcon)),
+			out: fmt.Sprintf(`{"path":%q,"type":"fileicon"}`, alt),
+		},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Fallback", i), func(t *testing.T) {
+			t.Parallel()
+			testMarshalJSON(t, i, test.in, test.out)
+		})
+	}
+}
+
+func TestIcon_FallbackCopy(t *testing.T) {
+	t.Parallel()
+
+	base := NewIcon("a.png").Fallback(NewIcon("b.png"))
+	_ = base.Fallback(NewIcon("c.png"))
+
+	if got := len(base.fallbacks); got != 1 {
+		t.Errorf("got: %d fallbacks want: %d", got, 1)
+	}
+
+	if IconGenericDocument.Fallback(IconTrash) == IconGenericDocument || len(IconGenericDocument.fallbacks) != 0 {
+		t.Error("got: modified system icon want: copy")
+	}
+}
+
+func TestScriptFilter_MissingIcons(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"icon.png": "png"})
+
+	icon := filepath.Join(dir, "icon.png")
+	missing := filepath.Join(dir, "missing.png")
+	typo := filepath.Join(dir, "typo.png")
+
+	sf := NewScriptFilter()
+	sf.Items().Append(
+		NewItem("ok").Icon(NewIcon(icon)),
+		NewItem("none"),
+		NewItem("bad").Icon(NewIcon(missing)),
+		nil,
+		NewItem("fallback").Icon(NewIcon(missing).Fallback(NewIcon(typo), NewIcon(icon))),
+		NewItem("mod").ModCmd(NewModifier().Icon(NewIcon(typo))),
+		NewItem("filetype").Icon(UTIFolder.Icon()),
+	)
+
+	want := []string{
+		fmt.Sprintf("icon %q of item %q not found", missing, "bad"),
+		fmt.Sprintf("icon %q of item %q not found, using %q", missing, "fallback", icon),
+		fmt.Sprintf("icon %q of item %q not found, using %q", typo, "fallback", icon),
+		fmt.Sprintf("icon %q of cmd modifier of item %q not found", typo, "mod"),
+	}
+
+	if got := sf.MissingIcons(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %q want: %q", got, want)
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// icon fetcher defaults.
+const (
+	DefaultIconMaxAge  = 7 * 24 * time.Hour
+	DefaultIconMaxSize = 1 << 20
+	DefaultIconWorkers = 4
+	DefaultIconTimeout = time.Second
+)
+
+// iconCacheDir is the directory in the workflow cache storing fetched icons.
+const iconCacheDir = "icons"
+
+// icon fetching errors.
+var (
+	ErrIconTooLarge = errors.New("icon is too large")
+	ErrNotImage     = errors.New("response is not an image")
+)
+
+// IconFetcher downloads remote icons, such as avatars and favicons, to a cache
+// directory so Alfred can show them.
+type IconFetcher struct {
+	dir      string
+	maxAge   time.Duration
+	maxSize  int64
+	workers  int
+	timeout  time.Duration
+	client   *http.Client
+	fallback *Icon
+	now      func() time.Time
+}
+
+// NewIconFetcher returns an IconFetcher caching icons in the icons directory of CacheDir.
+func NewIconFetcher() *IconFetcher {
+	dir := ""
+	if cache := CacheDir(); cache != "" {
+		dir = filepath.Join(cache, iconCacheDir)
+	}
+
+	return &IconFetcher{
+		dir:      dir,
+		maxAge:   DefaultIconMaxAge,
+		maxSize:  DefaultIconMaxSize,
+		workers:  DefaultIconWorkers,
+		timeout:  DefaultIconTimeout,
+		client:   http.DefaultClient,
+		fallback: IconGenericURL,
+		now:      time.Now,
+	}
+}
+
+// Dir sets the directory storing the icons.
+func (f *IconFetcher) Dir(dir string) *IconFetcher {
+	f.dir = dir
+
+	return f
+}
+
+// MaxAge sets the age after which a cached icon is downloaded again.
+func (f *IconFetcher) MaxAge(maxAge time.Duration) *IconFetcher {
+	f.maxAge = maxAge
+
+	return f
+}
+
+// MaxSize sets the maximum size in bytes of an icon.
+func (f *IconFetcher) MaxSize(maxSize int64) *IconFetcher {
+	f.maxSize = maxSize
+
+	return f
+}
+
+// Workers sets the maximum number of concurrent downloads.
+func (f *IconFetcher) Workers(workers int) *IconFetcher {
+	f.workers = workers
+
+	return f
+}
+
+// Timeout sets the time Icons waits for downloads before using fallbacks.
+func (f *IconFetcher) Timeout(timeout time.Duration) *IconFetcher {
+	f.timeout = timeout
+
+	return f
+}
+
+// Client sets the HTTP client used for downloads.
+func (f *IconFetcher) Client(client *http.Client) *IconFetcher {
+	f.client = client
+
+	return f
+}
+
+// Fallback sets the icon used when an icon cannot be fetched in time.
+func (f *IconFetcher) Fallback(icon *Icon) *IconFetcher {
+	f.fallback = icon
+
+	return f
+}
+
+// Path returns the path of the cached icon of rawURL, named after the hash of
+// the URL and the extension of its path.
+func (f *IconFetcher) Path(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	name := hex.EncodeToString(sum[:])
+
+	if u, err := url.Parse(rawURL); err == nil {
+		if ext := strings.ToLower(path.Ext(u.Path)); iconExtension(ext) {
+			name += ext
+		}
+	}
+
+	return filepath.Join(f.dir, name)
+}
+
+// Fetch returns the path of the cached icon of rawURL, downloading it unless
+// a cached copy is younger than the max age.
+func (f *IconFetcher) Fetch(ctx context.Context, rawURL string) (string, error) {
+	if f.dir == "" {
+		return "", ErrNoWorkflowDir
+	}
+
+	file := f.Path(rawURL)
+	if f.fresh(file) {
+		return file, nil
+	}
+
+	if err := f.download(ctx, rawURL, file); err != nil {
+		return "", err
+	}
+
+	return file, nil
+}
+
+// Icon is like Icons for a single URL.
+func (f *IconFetcher) Icon(ctx context.Context, rawURL string) *Icon {
+	return f.Icons(ctx, rawURL)[0]
+}
+
+// Icons returns the icons of the URLs in order, fetching them with a bounded
+// number of workers for at most the timeout.
+//
+// An icon which cannot be fetched in time is a previously cached copy, however
+// old, or else the fallback icon.
+func (f *IconFetcher) Icons(ctx context.Context, urls ...string) []*Icon {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		queue = make(map[string]struct{})
+		sem   = make(chan struct{}, maxInt(f.workers, 1))
+	)
+
+	for _, u := range urls {
+		if _, ok := queue[u]; ok {
+			continue
+		}
+
+		queue[u] = struct{}{}
+
+		wg.Add(1)
+
+		go func(u string) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			_, _ = f.Fetch(ctx, u) // failures use a stale copy or the fallback
+		}(u)
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	icons := make([]*Icon, len(urls))
+
+	for i, u := range urls {
+		file := f.Path(u)
+
+		if _, err := os.Stat(file); err == nil && f.dir != "" {
+			icons[i] = NewIcon(file)
+		} else {
+			icons[i] = f.fallback
+		}
+	}
+
+	return icons
+}
+
+func (f *IconFetcher) fresh(file string) bool {
+	info, err := os.Stat(file)
+
+	return err == nil && f.now().Sub(info.ModTime()) < f.maxAge
+}
+
+func (f *IconFetcher) download(ctx context.Context, rawURL, file string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("icon request: %w", err)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("icon download: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s: %s", ErrHTTPStatus, rawURL, resp.Status)
+	}
+
+	if ct := resp.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "image/") {
+		return fmt.Errorf("%w: %s: %s", ErrNotImage, rawURL, ct)
+	}
+
+	if _, err := EnsureDir(f.dir); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(f.dir, ".download-*")
+	if err != nil {
+		return err //nolint:wrapcheck // os errors include the path
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, f.maxSize+1))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	switch {
+	case err != nil:
+		return fmt.Errorf("icon download: %w", err)
+	case n > f.maxSize:
+		return fmt.Errorf("%w: %s: more than %d bytes", ErrIconTooLarge, rawURL, f.maxSize)
+	}
+
+	return os.Rename(tmp.Name(), file) //nolint:wrapcheck // os errors include the path
+}
+
+func iconExtension(ext string) bool {
+	switch ext {
+	case ".png", ".jpg", ".jpeg", ".gif", ".ico", ".icns", ".svg", ".webp", ".tif", ".tiff", ".bmp":
+		return true
+	default:
+		return false
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,188 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testIconServer struct {
+	*httptest.Server
+	requests int32
+	release  chan struct{}
+}
+
+func newTestIconServer(t *testing.T) *testIconServer {
+	t.Helper()
+
+	s := &testIconServer{release: make(chan struct{})}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/avatar.png", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.requests, 1)
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("png"))
+	})
+	mux.HandleFunc("/large.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+	})
+	mux.HandleFunc("/page.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html>"))
+	})
+	mux.HandleFunc("/slow.png", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-s.release:
+		case <-r.Context().Done():
+		}
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(func() {
+		close(s.release)
+		s.Close()
+	})
+
+	return s
+}
+
+func TestIconFetcher_Path(t *testing.T) {
+	t.Parallel()
+
+	f := NewIconFetcher().Dir("/cache/icons")
+
+	if got := f.Path("https://example.com/a/avatar.PNG?s=64"); !strings.HasPrefix(got, "/cache/icons/") ||
+		!strings.HasSuffix(got, ".png") || len(filepath.Base(got)) != 64+4 {
+		t.Errorf("got: %s", got)
+	}
+
+	if f.Path("https://example.com/a") == f.Path("https://example.com/b") {
+		t.Errorf("got: the same path for different URLs")
+	}
+
+	if got := filepath.Base(f.Path("https://example.com/favicon")); len(got) != 64 {
+		t.Errorf("got: %s want: no extension", got)
+	}
+}
+
+func TestIconFetcher_Fetch(t *testing.T) {
+	t.Parallel()
+
+	s := newTestIconServer(t)
+	f := NewIconFetcher().Dir(filepath.Join(t.TempDir(), "icons")).Client(s.Client()).MaxSize(10)
+	now := time.Now()
+	f.now = func() time.Time { return now }
+
+	file, err := f.Fetch(context.Background(), s.URL+"/avatar.png")
+	if err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+
+	if data, err := os.ReadFile(file); err != nil || string(data) != "png" {
+		t.Errorf("got: %q, %v want: png", data, err)
+	}
+
+	if _, err := f.Fetch(context.Background(), s.URL+"/avatar.png"); err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+
+	now = now.Add(DefaultIconMaxAge + time.Minute)
+
+	if _, err := f.Fetch(context.Background(), s.URL+"/avatar.png"); err != nil {
+		t.Fatalf("fetch error: %v", err)
+	}
+
+	if n := atomic.LoadInt32(&s.requests); n != 2 {
+		t.Errorf("got: %d requests want: 2", n)
+	}
+
+	type Test struct {
+		path string
+		err  error
+	}
+
+	for i, test := range []Test{
+		{path: "/large.png", err: ErrIconTooLarge},
+		{path: "/page.html", err: ErrNotImage},
+		{path: "/missing.png", err: ErrHTTPStatus},
+	} {
+		if _, err := f.Fetch(context.Background(), s.URL+test.path); !errors.Is(err, test.err) {
+			t.Errorf("#%d: got: %v want: %v", i, err, test.err)
+		}
+
+		if _, err := os.Stat(f.Path(s.URL + test.path)); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("#%d: got: a cached file", i)
+		}
+	}
+
+	if _, err := NewIconFetcher().Dir("").Fetch(context.Background(), s.URL+"/avatar.png"); !errors.Is(err, ErrNoWorkflowDir) {
+		t.Errorf("got: %v want: %v", err, ErrNoWorkflowDir)
+	}
+}
+
+func TestIconFetcher_Icons(t *testing.T) {
+	t.Parallel()
+
+	s := newTestIconServer(t)
+	f := NewIconFetcher().
+		Dir(t.TempDir()).
+		Client(s.Client()).
+		Workers(2).
+		Timeout(200 * time.Millisecond).
+		Fallback(IconGenericQuestionMark)
+
+	start := time.Now()
+	icons := f.Icons(context.Background(), s.URL+"/avatar.png", s.URL+"/slow.png", s.URL+"/page.html", s.URL+"/avatar.png")
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("got: %s want: the timeout", elapsed)
+	}
+
+	avatar := NewIcon(f.Path(s.URL + "/avatar.png"))
+	want := []*Icon{avatar, IconGenericQuestionMark, IconGenericQuestionMark, avatar}
+
+	for i := range icons {
+		testMarshalJSON(t, i, icons[i], mustMarshal(t, want[i]))
+	}
+
+	if n := atomic.LoadInt32(&s.requests); n != 1 {
+		t.Errorf("got: %d requests want: 1", n)
+	}
+
+	// A stale copy is used when the icon cannot be downloaded again.
+	offline := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("offline") //nolint:goerr113 // test
+	})}
+	stale := NewIconFetcher().Dir(f.dir).Client(offline).MaxAge(0)
+
+	testMarshalJSON(t, 0, stale.Icon(context.Background(), s.URL+"/avatar.png"), mustMarshal(t, avatar))
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func mustMarshal(t *testing.T, icon *Icon) string {
+	t.Helper()
+
+	data, err := icon.MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+
+	return string(data)
+}
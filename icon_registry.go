@@ -0,0 +1,400 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// icon registry errors.
+var (
+	ErrUnknownIcon   = errors.New("unknown icon")
+	ErrImmutableIcon = errors.New("system icons cannot be modified")
+)
+
+type namedIcon struct {
+	name string
+	icon *Icon
+}
+
+// iconRegistry holds the icons registered by the workflow by normalized name.
+//
+//nolint:gochecknoglobals // registry shared by the workflow
+var iconRegistry = struct {
+	sync.RWMutex
+	icons   map[string]namedIcon
+	aliases map[string]string
+}{
+	icons:   make(map[string]namedIcon),
+	aliases: make(map[string]string),
+}
+
+// systemIconIndex holds the system icons, built once on first use.
+//
+//nolint:gochecknoglobals // built once and shared by lookups
+var systemIconIndex struct {
+	once   sync.Once
+	byKey  map[string]namedIcon
+	system map[*Icon]bool
+}
+
+// systemIconsByKey returns the system icons by normalized name.
+func systemIconsByKey() map[string]namedIcon {
+	systemIconIndex.once.Do(func() {
+		icons := systemIcons()
+		systemIconIndex.byKey = make(map[string]namedIcon, len(icons))
+		systemIconIndex.system = make(map[*Icon]bool, len(icons))
+
+		for _, ni := range icons {
+			systemIconIndex.byKey[normalizeIconName(ni.name)] = ni
+			systemIconIndex.system[ni.icon] = true
+		}
+	})
+
+	return systemIconIndex.byKey
+}
+
+// systemIcons returns the system icons named after their variable, without the Icon prefix.
+func systemIcons() []namedIcon {
+	return []namedIcon{
+		{name: "ARDocument", icon: IconARDocument},
+		{name: "ARObject", icon: IconARObject},
+		{name: "Accounts", icon: IconAccounts},
+		{name: "Actions", icon: IconActions},
+		{name: "AirDrop", icon: IconAirDrop},
+		{name: "AlertCautionBadge", icon: IconAlertCautionBadge},
+		{name: "AlertNote", icon: IconAlertNote},
+		{name: "AlertStop", icon: IconAlertStop},
+		{name: "AliasBadge", icon: IconAliasBadge},
+		{name: "AllMyFiles", icon: IconAllMyFiles},
+		{name: "ApplicationsFolder", icon: IconApplicationsFolder},
+		{name: "BackwardArrow", icon: IconBackwardArrow},
+		{name: "Bonjour", icon: IconBonjour},
+		{name: "Bookmark", icon: IconBookmark},
+		{name: "BurnableFolder", icon: IconBurnableFolder},
+		{name: "Burning", icon: IconBurning},
+		{name: "CDAudioVolume", icon: IconCDAudioVolume},
+		{name: "ClippingPicture", icon: IconClippingPicture},
+		{name: "ClippingSound", icon: IconClippingSound},
+		{name: "ClippingText", icon: IconClippingText},
+		{name: "ClippingUnknown", icon: IconClippingUnknown},
+		{name: "Clock", icon: IconClock},
+		{name: "ColorSyncProfile", icon: IconColorSyncProfile},
+		{name: "ConnectTo", icon: IconConnectTo},
+		{name: "DesktopFolder", icon: IconDesktopFolder},
+		{name: "DeveloperFolder", icon: IconDeveloperFolder},
+		{name: "DocumentsFolder", icon: IconDocumentsFolder},
+		{name: "DownloadsFolder", icon: IconDownloadsFolder},
+		{name: "DropFolderBadge", icon: IconDropFolderBadge},
+		{name: "EjectMedia", icon: IconEjectMedia},
+		{name: "Erasing", icon: IconErasing},
+		{name: "Everyone", icon: IconEveryone},
+		{name: "ExecutableBinary", icon: IconExecutableBinary},
+		{name: "FavoriteItems", icon: IconFavoriteItems},
+		{name: "FileVault", icon: IconFileVault},
+		{name: "Finder", icon: IconFinder},
+		{name: "ForwardArrow", icon: IconForwardArrow},
+		{name: "FullTrash", icon: IconFullTrash},
+		{name: "General", icon: IconGeneral},
+		{name: "GenericAirDisk", icon: IconGenericAirDisk},
+		{name: "GenericApplication", icon: IconGenericApplication},
+		{name: "GenericDocument", icon: IconGenericDocument},
+		{name: "GenericFileServer", icon: IconGenericFileServer},
+		{name: "GenericFolder", icon: IconGenericFolder},
+		{name: "GenericFont", icon: IconGenericFont},
+		{name: "GenericNetwork", icon: IconGenericNetwork},
+		{name: "GenericQuestionMark", icon: IconGenericQuestionMark},
+		{name: "GenericSharepoint", icon: IconGenericSharepoint},
+		{name: "GenericSpeaker", icon: IconGenericSpeaker},
+		{name: "GenericStationery", icon: IconGenericStationery},
+		{name: "GenericTimeMachineDisk", icon: IconGenericTimeMachineDisk},
+		{name: "GenericURL", icon: IconGenericURL},
+		{name: "GenericWindow", icon: IconGenericWindow},
+		{name: "Grid", icon: IconGrid},
+		{name: "GroupFolder", icon: IconGroupFolder},
+		{name: "Group", icon: IconGroup},
+		{name: "GuestUser", icon: IconGuestUser},
+		{name: "Help", icon: IconHelp},
+		{name: "HomeFolder", icon: IconHomeFolder},
+		{name: "InternetLocation", icon: IconInternetLocation},
+		{name: "KEXT", icon: IconKEXT},
+		{name: "KeepArranged", icon: IconKeepArranged},
+		{name: "LibraryFolder", icon: IconLibraryFolder},
+		{name: "LockedBadge", icon: IconLockedBadge},
+		{name: "Locked", icon: IconLocked},
+		{name: "MagnifyingGlass", icon: IconMagnifyingGlass},
+		{name: "MovieFolder", icon: IconMovieFolder},
+		{name: "MultipleItems", icon: IconMultipleItems},
+		{name: "MusicFolder", icon: IconMusicFolder},
+		{name: "NetBootVolume", icon: IconNetBootVolume},
+		{name: "NewFolderBadge", icon: IconNewFolderBadge},
+		{name: "NoWrite", icon: IconNoWrite},
+		{name: "NotLoaded", icon: IconNotLoaded},
+		{name: "Notifications", icon: IconNotifications},
+		{name: "OpenFolder", icon: IconOpenFolder},
+		{name: "PicturesFolder", icon: IconPicturesFolder},
+		{name: "PrivateFolderBadge", icon: IconPrivateFolderBadge},
+		{name: "ProblemReport", icon: IconProblemReport},
+		{name: "ProfileBackgroundColor", icon: IconProfileBackgroundColor},
+		{name: "ProfileFont", icon: IconProfileFont},
+		{name: "ProfileFontAndColor", icon: IconProfileFontAndColor},
+		{name: "PublicFolder", icon: IconPublicFolder},
+		{name: "ReadOnlyFolderBadge", icon: IconReadOnlyFolderBadge},
+		{name: "RealityFile", icon: IconRealityFile},
+		{name: "RecentItems", icon: IconRecentItems},
+		{name: "RightContainerArrow", icon: IconRightContainerArrow},
+		{name: "ServerApplicationsFolder", icon: IconServerApplicationsFolder},
+		{name: "SidebarAirDrop", icon: IconSidebarAirDrop},
+		{name: "SidebarAirportDisk", icon: IconSidebarAirportDisk},
+		{name: "SidebarAirportExpress", icon: IconSidebarAirportExpress},
+		{name: "SidebarAirportExtreme", icon: IconSidebarAirportExtreme},
+		{name: "SidebarAirportExtremeTower", icon: IconSidebarAirportExtremeTower},
+		{name: "SidebarAllMyFiles", icon: IconSidebarAllMyFiles},
+		{name: "SidebarApplicationsFolder", icon: IconSidebarApplicationsFolder},
+		{name: "SidebarBonjour", icon: IconSidebarBonjour},
+		{name: "SidebarBurnFolder", icon: IconSidebarBurnFolder},
+		{name: "SidebarDesktopFolder", icon: IconSidebarDesktopFolder},
+		{name: "SidebarDisplay", icon: IconSidebarDisplay},
+		{name: "SidebarDocumentsFolder", icon: IconSidebarDocumentsFolder},
+		{name: "SidebarDownloadsFolder", icon: IconSidebarDownloadsFolder},
+		{name: "SidebarDropBoxFolder", icon: IconSidebarDropBoxFolder},
+		{name: "SidebarExternalDisk", icon: IconSidebarExternalDisk},
+		{name: "SidebarGenericFile", icon: IconSidebarGenericFile},
+		{name: "SidebarGenericFolder", icon: IconSidebarGenericFolder},
+		{name: "SidebarHomeFolder", icon: IconSidebarHomeFolder},
+		{name: "SidebarInternalDisk", icon: IconSidebarInternalDisk},
+		{name: "SidebarLaptop", icon: IconSidebarLaptop},
+		{name: "SidebarMacMini", icon: IconSidebarMacMini},
+		{name: "SidebarMacPro", icon: IconSidebarMacPro},
+		{name: "SidebarMacProCylinder", icon: IconSidebarMacProCylinder},
+		{name: "SidebarMoviesFolder", icon: IconSidebarMoviesFolder},
+		{name: "SidebarMusicFolder", icon: IconSidebarMusicFolder},
+		{name: "SidebarNetwork", icon: IconSidebarNetwork},
+		{name: "SidebarOpticalDisk", icon: IconSidebarOpticalDisk},
+		{name: "SidebarPC", icon: IconSidebarPC},
+		{name: "SidebarPicturesFolder", icon: IconSidebarPicturesFolder},
+		{name: "SidebarPrefs", icon: IconSidebarPrefs},
+		{name: "SidebarRecents", icon: IconSidebarRecents},
+		{name: "SidebarRemovableDisk", icon: IconSidebarRemovableDisk},
+		{name: "SidebarServerDrive", icon: IconSidebarServerDrive},
+		{name: "SidebarSmartFolder", icon: IconSidebarSmartFolder},
+		{name: "SidebarTimeCapsule", icon: IconSidebarTimeCapsule},
+		{name: "SidebarTimeMachine", icon: IconSidebarTimeMachine},
+		{name: "SidebarUtilitiesFolder", icon: IconSidebarUtilitiesFolder},
+		{name: "SidebarXserve", icon: IconSidebarXserve},
+		{name: "SidebarICloud", icon: IconSidebarICloud},
+		{name: "SidebarIDisk", icon: IconSidebarIDisk},
+		{name: "SidebarIMac", icon: IconSidebarIMac},
+		{name: "SidebarIPad", icon: IconSidebarIPad},
+		{name: "SidebarIPhone", icon: IconSidebarIPhone},
+		{name: "SidebarIPodTouch", icon: IconSidebarIPodTouch},
+		{name: "SitesFolder", icon: IconSitesFolder},
+		{name: "SmartFolder", icon: IconSmartFolder},
+		{name: "Sync", icon: IconSync},
+		{name: "SystemFolder", icon: IconSystemFolder},
+		{name: "ToolbarAdvanced", icon: IconToolbarAdvanced},
+		{name: "ToolbarCustomize", icon: IconToolbarCustomize},
+		{name: "ToolbarDelete", icon: IconToolbarDelete},
+		{name: "ToolbarFavorites", icon: IconToolbarFavorites},
+		{name: "ToolbarInfo", icon: IconToolbarInfo},
+		{name: "ToolbarLabels", icon: IconToolbarLabels},
+		{name: "Trash", icon: IconTrash},
+		{name: "UnknownFSObject", icon: IconUnknownFSObject},
+		{name: "Unlocked", icon: IconUnlocked},
+		{name: "Unsupported", icon: IconUnsupported},
+		{name: "User", icon: IconUser},
+		{name: "UserUnknown", icon: IconUserUnknown},
+		{name: "UsersFolder", icon: IconUsersFolder},
+		{name: "UtilitiesFolder", icon: IconUtilitiesFolder},
+		{name: "VCard", icon: IconVCard},
+		{name: "IDiskGeneric", icon: IconIDiskGeneric},
+		{name: "IDiskUser", icon: IconIDiskUser},
+		{name: "PublicGenericLCD", icon: IconPublicGenericLCD},
+		{name: "PublicGenericPC", icon: IconPublicGenericPC},
+	}
+}
+
+func isSystemIcon(icon *Icon) bool {
+	systemIconsByKey()
+
+	return systemIconIndex.system[icon]
+}
+
+// iconAliases returns common names of system icons.
+func iconAliases() map[string]string {
+	return map[string]string{
+		"application": "GenericApplication",
+		"app":         "GenericApplication",
+		"delete":      "Trash",
+		"document":    "GenericDocument",
+		"error":       "AlertStop",
+		"file":        "GenericDocument",
+		"folder":      "GenericFolder",
+		"home":        "HomeFolder",
+		"info":        "ToolbarInfo",
+		"lock":        "Locked",
+		"network":     "GenericNetwork",
+		"note":        "AlertNote",
+		"question":    "GenericQuestionMark",
+		"search":      "MagnifyingGlass",
+		"settings":    "ToolbarAdvanced",
+		"time":        "Clock",
+		"unlock":      "Unlocked",
+		"update":      "Sync",
+		"url":         "GenericURL",
+		"warning":     "AlertCautionBadge",
+		"web":         "GenericURL",
+	}
+}
+
+// normalizeIconName folds case and ignores an Icon prefix, spaces, dashes and underscores,
+// so "IconAlertStop", "alert-stop" and "Alert Stop" are the same name.
+func normalizeIconName(name string) string {
+	name = strings.ToLower(strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.TrimSpace(name)))
+	if trimmed := strings.TrimPrefix(name, "icon"); trimmed != "" {
+		name = trimmed
+	}
+
+	return name
+}
+
+// RegisterIcon registers icon under name, such as an icon bundled with the workflow.
+// A registered icon takes precedence over a system icon of the same name.
+func RegisterIcon(name string, icon *Icon) {
+	iconRegistry.Lock()
+	defer iconRegistry.Unlock()
+
+	iconRegistry.icons[normalizeIconName(name)] = namedIcon{name: name, icon: icon}
+}
+
+// RegisterIconAlias registers alias as another name of the icon name.
+func RegisterIconAlias(alias, name string) error {
+	if _, ok := IconByName(name); !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownIcon, name)
+	}
+
+	iconRegistry.Lock()
+	defer iconRegistry.Unlock()
+
+	iconRegistry.aliases[normalizeIconName(alias)] = name
+
+	return nil
+}
+
+// IconByName returns the registered or system icon called name, or an alias of it.
+// Names are case-insensitive, and may be written with or without the Icon prefix.
+func IconByName(name string) (*Icon, bool) {
+	const maxAliasDepth = 8
+
+	return iconByName(normalizeIconName(name), maxAliasDepth)
+}
+
+func iconByName(key string, depth int) (*Icon, bool) {
+	iconRegistry.RLock()
+	registered, ok := iconRegistry.icons[key]
+	alias, isAlias := iconRegistry.aliases[key]
+	iconRegistry.RUnlock()
+
+	if ok {
+		return registered.icon, true
+	}
+
+	if ni, ok := systemIconsByKey()[key]; ok {
+		return ni.icon, true
+	}
+
+	if !isAlias {
+		alias, isAlias = iconAliases()[key]
+	}
+
+	if isAlias && depth > 0 {
+		return iconByName(normalizeIconName(alias), depth-1)
+	}
+
+	return nil, false
+}
+
+// RangeIcons calls fn for each registered and system icon, sorted by name,
+// until fn returns false. Aliases are not included.
+func RangeIcons(fn func(name string, icon *Icon) bool) {
+	system := systemIconsByKey()
+
+	icons := make(map[string]namedIcon, len(system))
+	for key, ni := range system {
+		icons[key] = ni
+	}
+
+	iconRegistry.RLock()
+	for key, ni := range iconRegistry.icons {
+		icons[key] = ni
+	}
+	iconRegistry.RUnlock()
+
+	all := make([]namedIcon, 0, len(icons))
+	for _, ni := range icons {
+		all = append(all, ni)
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].name < all[j].name })
+
+	for _, ni := range all {
+		if !fn(ni.name, ni.icon) {
+			return
+		}
+	}
+}
+
+// IconNames returns the names of the registered and system icons, sorted.
+func IconNames() []string {
+	var names []string
+
+	RangeIcons(func(name string, _ *Icon) bool {
+		names = append(names, name)
+
+		return true
+	})
+
+	return names
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+//
+// An icon is either a name known to IconByName or a path given as a string,
+// or an object with the path and type of the Alfred JSON format.
+// Unmarshaling into a system icon returns ErrImmutableIcon.
+func (i *Icon) UnmarshalJSON(data []byte) error {
+	if isSystemIcon(i) {
+		return ErrImmutableIcon
+	}
+
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		if icon, ok := IconByName(name); ok {
+			*i = *icon
+		} else {
+			*i = Icon{path: name}
+		}
+
+		return nil
+	}
+
+	var v struct {
+		Path string    `json:"path"`
+		Type *IconType `json:"type"`
+	}
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("icon: %w", err)
+	}
+
+	*i = Icon{path: v.Path, typ: v.Type}
+
+	return nil
+}
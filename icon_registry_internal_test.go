@@ -0,0 +1,141 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestIconByName(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in  string
+		out *Icon
+	}
+
+	tests := []Test{
+		{in: "Trash", out: IconTrash},
+		{in: "trash", out: IconTrash},
+		{in: "IconTrash", out: IconTrash},
+		{in: "alert-caution_badge", out: IconAlertCautionBadge},
+		{in: "Alert Stop", out: IconAlertStop},
+		{in: "IDiskGeneric", out: IconIDiskGeneric},
+		{in: "warning", out: IconAlertCautionBadge},
+		{in: "Folder", out: IconGenericFolder},
+		{in: "missing", out: nil},
+		{in: "", out: nil},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:IconByName", i), func(t *testing.T) {
+			t.Parallel()
+			got, ok := IconByName(test.in)
+			if got != test.out || ok != (test.out != nil) {
+				t.Errorf("#%d: got: %v, %t want: %v", i, got, ok, test.out)
+			}
+		})
+	}
+}
+
+func TestRegisterIcon(t *testing.T) {
+	logo := NewIcon("./icons/logo.png")
+	RegisterIcon("TestLogo", logo)
+
+	if got, ok := IconByName("test-logo"); !ok || got != logo {
+		t.Errorf("got: %v, %t want: %v", got, ok, logo)
+	}
+
+	if err := RegisterIconAlias("test-brand", "TestLogo"); err != nil {
+		t.Fatalf("alias error: %v", err)
+	}
+
+	if got, _ := IconByName("TestBrand"); got != logo {
+		t.Errorf("alias: got: %v want: %v", got, logo)
+	}
+
+	if err := RegisterIconAlias("test-other", "test-missing"); !errors.Is(err, ErrUnknownIcon) {
+		t.Errorf("got: %v want: %v", err, ErrUnknownIcon)
+	}
+
+	// aliases referring to each other do not loop.
+	if err := RegisterIconAlias("test-loop", "test-brand"); err != nil {
+		t.Fatalf("alias error: %v", err)
+	}
+
+	if err := RegisterIconAlias("test-brand", "test-loop"); err != nil {
+		t.Fatalf("alias error: %v", err)
+	}
+
+	if got, ok := IconByName("test-loop"); ok {
+		t.Errorf("got: %v want: not found", got)
+	}
+}
+
+func TestRangeIcons(t *testing.T) {
+	t.Parallel()
+
+	names := IconNames()
+	if len(names) < 154 {
+		t.Errorf("got: %d names want: at least 154", len(names))
+	}
+
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("names are not sorted")
+	}
+
+	seen := 0
+
+	RangeIcons(func(name string, icon *Icon) bool {
+		if got, ok := IconByName(name); !ok || got != icon {
+			t.Errorf("%s: got: %v want: %v", name, got, icon)
+		}
+
+		seen++
+
+		return seen < 10
+	})
+
+	if seen != 10 {
+		t.Errorf("got: %d icons want: 10", seen)
+	}
+}
+
+func TestIcon_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in  string
+		out string
+	}
+
+	tests := []Test{
+		{in: `"trash"`, out: `{"path":"` + systemIconDirPath + `TrashIcon.icns"}`},
+		{in: `"./icon.png"`, out: `{"path":"./icon.png"}`},
+		{in: `{"path":"~/Desktop","type":"fileicon"}`, out: `{"path":"~/Desktop","type":"fileicon"}`},
+		{in: `{"path":"./icon.png"}`, out: `{"path":"./icon.png"}`},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:UnmarshalJSON", i), func(t *testing.T) {
+			t.Parallel()
+			got := new(Icon)
+			if err := json.Unmarshal([]byte(test.in), got); err != nil {
+				t.Fatalf("#%d: unmarshal error: %v", i, err)
+			}
+			testMarshalJSON(t, i, got, test.out)
+		})
+	}
+
+	if err := json.Unmarshal([]byte(`1`), new(Icon)); err == nil {
+		t.Errorf("got: no error for a number")
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package shape draws the simple shapes of generated icons.
+package shape
+
+import (
+	"image"
+	"image/color"
+)
+
+// RoundedSquare returns a size by size image of a square with rounded corners
+// of the given radius, each pixel colored by fill, on a transparent background.
+func RoundedSquare(size, radius int, fill func(x, y int) color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if insideRoundedSquare(x, y, size, radius) {
+				img.SetNRGBA(x, y, fill(x, y))
+			}
+		}
+	}
+
+	return img
+}
+
+func insideRoundedSquare(x, y, size, radius int) bool {
+	cx, cy := x, y
+
+	switch {
+	case x < radius:
+		cx = radius
+	case x >= size-radius:
+		cx = size - radius - 1
+	}
+
+	switch {
+	case y < radius:
+		cy = radius
+	case y >= size-radius:
+		cy = size - radius - 1
+	}
+
+	dx, dy := x-cx, y-cy
+
+	return dx*dx+dy*dy <= radius*radius
+}
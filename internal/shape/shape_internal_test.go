@@ -0,0 +1,43 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package shape
+
+import (
+	"fmt"
+	"image/color"
+	"testing"
+)
+
+func TestRoundedSquare(t *testing.T) {
+	t.Parallel()
+
+	fill := color.NRGBA{R: 0xff, A: 0xff}
+	img := RoundedSquare(32, 8, func(x, y int) color.NRGBA { return fill })
+
+	type Test struct {
+		x, y int
+		out  color.NRGBA
+	}
+
+	tests := []Test{
+		{x: 0, y: 0, out: color.NRGBA{}},
+		{x: 31, y: 31, out: color.NRGBA{}},
+		{x: 0, y: 16, out: fill},
+		{x: 16, y: 0, out: fill},
+		{x: 16, y: 16, out: fill},
+		{x: 3, y: 3, out: fill},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:RoundedSquare", i), func(t *testing.T) {
+			t.Parallel()
+
+			if got := img.NRGBAAt(test.x, test.y); got != test.out {
+				t.Errorf("#%d: got: %v want: %v", i, got, test.out)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// AlfredAppBundleID is the bundle ID of the Alfred application.
+const AlfredAppBundleID = "com.runningwithcrayons.Alfred"
+
+// Invocation re-enters Alfred, either by its URL scheme or by scripting it.
+type Invocation interface {
+	// URL returns the alfred:// URL of the invocation.
+	URL() string
+	// AppleScript returns the AppleScript statement of the invocation.
+	AppleScript() string
+	// JXA returns the JavaScript for Automation statement of the invocation.
+	JXA() string
+	// query returns the text to autocomplete in Alfred, if any.
+	query() string
+}
+
+// Trigger invokes an External Trigger of a workflow.
+type Trigger struct {
+	name      string
+	bundleID  string
+	argument  *string
+	variables map[string]string
+}
+
+// NewTrigger returns a Trigger of the External Trigger name in the running workflow.
+func NewTrigger(name string) *Trigger {
+	return &Trigger{
+		name:     name,
+		bundleID: BundleID(),
+	}
+}
+
+// InWorkflow sets the bundle ID of the workflow of the External Trigger.
+func (t *Trigger) InWorkflow(bundleID string) *Trigger {
+	t.bundleID = bundleID
+
+	return t
+}
+
+// Argument sets the argument passed to the External Trigger.
+func (t *Trigger) Argument(argument string) *Trigger {
+	t.argument = &argument
+
+	return t
+}
+
+// Variable sets a variable passed to the External Trigger.
+//
+// Variables are passed by AppleScript and JXA only; the URL scheme has no equivalent.
+func (t *Trigger) Variable(key, value string) *Trigger {
+	if t.variables == nil {
+		t.variables = make(map[string]string)
+	}
+
+	t.variables[key] = value
+
+	return t
+}
+
+// URL returns an alfred://runtrigger URL.
+func (t *Trigger) URL() string {
+	u := "alfred://runtrigger/" + url.PathEscape(t.bundleID) + "/" + url.PathEscape(t.name) + "/"
+	if t.argument != nil {
+		u += "?argument=" + queryEscape(*t.argument)
+	}
+
+	return u
+}
+
+// AppleScript returns a "run trigger" statement.
+func (t *Trigger) AppleScript() string {
+	s := `tell application id "` + AlfredAppBundleID + `" to run trigger ` + appleScriptString(t.name) +
+		" in workflow " + appleScriptString(t.bundleID)
+	if t.argument != nil {
+		s += " with argument " + appleScriptString(*t.argument)
+	}
+
+	if len(t.variables) > 0 {
+		pairs := make([]string, 0, len(t.variables))
+		for _, key := range sortedKeys(t.variables) {
+			pairs = append(pairs, "|"+strings.ReplaceAll(key, "|", "")+"|:"+appleScriptString(t.variables[key]))
+		}
+
+		s += " with variables {" + strings.Join(pairs, ", ") + "}"
+	}
+
+	return s
+}
+
+// JXA returns a runTrigger statement.
+func (t *Trigger) JXA() string {
+	s := "Application(" + jsString(AlfredAppBundleID) + ").runTrigger(" + jsString(t.name) +
+		", {inWorkflow: " + jsString(t.bundleID)
+	if t.argument != nil {
+		s += ", withArgument: " + jsString(*t.argument)
+	}
+
+	if len(t.variables) > 0 {
+		pairs := make([]string, 0, len(t.variables))
+		for _, key := range sortedKeys(t.variables) {
+			pairs = append(pairs, jsString(key)+": "+jsString(t.variables[key]))
+		}
+
+		s += ", withVariables: {" + strings.Join(pairs, ", ") + "}"
+	}
+
+	return s + "})"
+}
+
+func (t *Trigger) query() string {
+	if t.argument == nil {
+		return ""
+	}
+
+	return *t.argument
+}
+
+// Search shows Alfred with a query.
+type Search struct {
+	text string
+}
+
+// NewSearch returns a Search for query.
+func NewSearch(query string) *Search {
+	return &Search{text: query}
+}
+
+// URL returns an alfred://search URL.
+func (s *Search) URL() string {
+	return "alfred://search/" + queryEscape(s.text)
+}
+
+// AppleScript returns a "search" statement.
+func (s *Search) AppleScript() string {
+	return `tell application id "` + AlfredAppBundleID + `" to search ` + appleScriptString(s.text)
+}
+
+// JXA returns a search statement.
+func (s *Search) JXA() string {
+	return "Application(" + jsString(AlfredAppBundleID) + ").search(" + jsString(s.text) + ")"
+}
+
+func (s *Search) query() string {
+	return s.text
+}
+
+// Osascript returns a shell command running the AppleScript of inv with osascript.
+func Osascript(inv Invocation) string {
+	return "osascript -e " + shellQuote(inv.AppleScript())
+}
+
+// OsascriptJXA returns a shell command running the JXA of inv with osascript.
+func OsascriptJXA(inv Invocation) string {
+	return "osascript -l JavaScript -e " + shellQuote(inv.JXA())
+}
+
+// Invoke sets the arg to the URL of inv, to be opened by an Open URL object,
+// and the autocomplete to its argument or query.
+func (i *Item) Invoke(inv Invocation) *Item {
+	i.Arg(inv.URL())
+
+	if q := inv.query(); q != "" {
+		i.Autocomplete(q)
+	}
+
+	return i
+}
+
+// queryEscape escapes s for a URL query, with spaces as %20 as Alfred expects.
+func queryEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func jsString(s string) string {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s) // strings always encode
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
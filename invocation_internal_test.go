@@ -0,0 +1,102 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestInvocation(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in          Invocation
+		url         string
+		appleScript string
+		jxa         string
+	}
+
+	tests := []Test{
+		{
+			in:          NewTrigger("open").InWorkflow("com.example.test"),
+			url:         "alfred://runtrigger/com.example.test/open/",
+			appleScript: `tell application id "com.runningwithcrayons.Alfred" to run trigger "open" in workflow "com.example.test"`,
+			jxa:         `Application("com.runningwithcrayons.Alfred").runTrigger("open", {inWorkflow: "com.example.test"})`,
+		},
+		{
+			in:  NewTrigger("my trigger").InWorkflow("com.example.test").Argument(`a "b" & c\d+e`),
+			url: "alfred://runtrigger/com.example.test/my%20trigger/?argument=a%20%22b%22%20%26%20c%5Cd%2Be",
+			appleScript: `tell application id "com.runningwithcrayons.Alfred" to run trigger "my trigger" ` +
+				`in workflow "com.example.test" with argument "a \"b\" & c\\d+e"`,
+			jxa: `Application("com.runningwithcrayons.Alfred").runTrigger("my trigger", ` +
+				`{inWorkflow: "com.example.test", withArgument: "a \"b\" & c\\d+e"})`,
+		},
+		{
+			in: NewTrigger("open").InWorkflow("com.example.test").Argument("x").
+				Variable("mode", "edit").Variable("id", "1"),
+			url: "alfred://runtrigger/com.example.test/open/?argument=x",
+			appleScript: `tell application id "com.runningwithcrayons.Alfred" to run trigger "open" ` +
+				`in workflow "com.example.test" with argument "x" with variables {|id|:"1", |mode|:"edit"}`,
+			jxa: `Application("com.runningwithcrayons.Alfred").runTrigger("open", ` +
+				`{inWorkflow: "com.example.test", withArgument: "x", withVariables: {"id": "1", "mode": "edit"}})`,
+		},
+		{
+			in:          NewSearch("g it's <b>"),
+			url:         "alfred://search/g%20it%27s%20%3Cb%3E",
+			appleScript: `tell application id "com.runningwithcrayons.Alfred" to search "g it's <b>"`,
+			jxa:         `Application("com.runningwithcrayons.Alfred").search("g it's <b>")`,
+		},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Invocation", i), func(t *testing.T) {
+			t.Parallel()
+			if got := test.in.URL(); got != test.url {
+				t.Errorf("#%d: url: got: %s want: %s", i, got, test.url)
+			}
+			if got := test.in.AppleScript(); got != test.appleScript {
+				t.Errorf("#%d: applescript: got: %s want: %s", i, got, test.appleScript)
+			}
+			if got := test.in.JXA(); got != test.jxa {
+				t.Errorf("#%d: jxa: got: %s want: %s", i, got, test.jxa)
+			}
+		})
+	}
+}
+
+func TestOsascript(t *testing.T) {
+	t.Parallel()
+
+	s := NewSearch("it's")
+
+	if got, want := Osascript(s),
+		`osascript -e 'tell application id "com.runningwithcrayons.Alfred" to search "it'\''s"'`; got != want {
+		t.Errorf("got: %s want: %s", got, want)
+	}
+
+	if got, want := OsascriptJXA(s),
+		`osascript -l JavaScript -e 'Application("com.runningwithcrayons.Alfred").search("it'\''s")'`; got != want {
+		t.Errorf("got: %s want: %s", got, want)
+	}
+}
+
+func TestTrigger_BundleID(t *testing.T) {
+	t.Setenv(EnvWorkflowBundleID, "com.example.env")
+
+	if got, want := NewTrigger("open").URL(), "alfred://runtrigger/com.example.env/open/"; got != want {
+		t.Errorf("got: %s want: %s", got, want)
+	}
+}
+
+func TestItem_Invoke(t *testing.T) {
+	t.Parallel()
+
+	testMarshalJSON(t, 0, NewItem("title").Invoke(NewSearch("g go")),
+		`{"title":"title","arg":"alfred://search/g%20go","autocomplete":"g go"}`)
+	testMarshalJSON(t, 1, NewItem("title").Invoke(NewTrigger("open").InWorkflow("com.example.test")),
+		`{"title":"title","arg":"alfred://runtrigger/com.example.test/open/"}`)
+}
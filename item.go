@@ -18,9 +18,15 @@ const (
 )
 
 // Icon represents the icon for Item.
+//
+// An Icon is never modified once created: its setters return a modified copy,
+// so shared icons such as IconTrash can be customized without side effects.
 type Icon struct {
-	path string
-	typ  *IconType
+	path      string
+	typ       *IconType
+	light     string
+	dark      string
+	fallbacks []*Icon
 }
 
 // NewIcon returns an Icon with the given path.
@@ -38,28 +44,50 @@ func NewIconWithType(path string, typ IconType) *Icon {
 	}
 }
 
-// Path sets the path of the Item for Alfred results.
+// Path returns a copy of the Icon with the given path.
 func (i *Icon) Path(path string) *Icon {
-	i.path = path
+	c := *i
+	c.path = path
 
-	return i
+	return &c
 }
 
-// Type sets the type of the Item for Alfred results.
+// Type returns a copy of the Icon with the given type.
 func (i *Icon) Type(typ IconType) *Icon {
-	i.typ = &typ
+	c := *i
+	c.typ = &typ
 
-	return i
+	return &c
+}
+
+// Light returns a copy of the Icon using path instead of the default path with a light Alfred theme.
+func (i *Icon) Light(path string) *Icon {
+	c := *i
+	c.light = path
+
+	return &c
+}
+
+// Dark returns a copy of the Icon using path instead of the default path with a dark Alfred theme.
+func (i *Icon) Dark(path string) *Icon {
+	c := *i
+	c.dark = path
+
+	return &c
 }
 
 // MarshalJSON implements the json.Marshaler interface.
+//
+// The path is chosen for the theme of the running Alfred, and the first
+// existing icon of the fallback chain is used.
 func (i *Icon) MarshalJSON() ([]byte, error) {
+	icon := i.available(CurrentTheme())
 	v := &struct {
 		Path string    `json:"path"`
 		Type *IconType `json:"type,omitempty"`
 	}{
-		Path: i.path,
-		Type: i.typ,
+		Path: icon.resolve(CurrentTheme()),
+		Type: icon.typ,
 	}
 
 	return json.Marshal(v)
@@ -464,6 +492,13 @@ func (i *Item) ModCmd(mod *Modifier) *Item {
 	return i
 }
 
+// Action sets the Action used by Universal Actions, available since Alfred 4.5.
+func (i *Item) Action(action *Action) *Item {
+	i.action = action
+
+	return i
+}
+
 // CopyText sets the copy text.
 func (i *Item) CopyText(text string) *Item {
 	if i.text == nil {
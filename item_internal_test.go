@@ -6,6 +6,7 @@ package alfred
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -74,6 +75,33 @@ func TestIcon_MarshalJSON(t *testing.T) {
 	}
 }
 
+func TestIcon_CopyOnWrite(t *testing.T) {
+	t.Parallel()
+
+	want := `{"path":"` + systemIconDirPath + `TrashIcon.icns"}`
+
+	trash := IconTrash.Type(IconTypeFileIcon).Path("./trash.png").Light("./light.png").Dark("./dark.png")
+	if trash == IconTrash {
+		t.Errorf("got: the shared icon want: a copy")
+	}
+
+	testMarshalJSON(t, 0, IconTrash, want)
+	testMarshalJSON(t, 1, trash, `{"path":"./trash.png","type":"fileicon"}`)
+
+	icon := NewIcon("./icon.png")
+	typed := icon.Type(IconTypeFileType)
+
+	testMarshalJSON(t, 2, icon, `{"path":"./icon.png"}`)
+	testMarshalJSON(t, 3, typed.Type(IconTypeFileIcon), `{"path":"./icon.png","type":"fileicon"}`)
+	testMarshalJSON(t, 4, typed, `{"path":"./icon.png","type":"filetype"}`)
+
+	if err := json.Unmarshal([]byte(`"./other.png"`), IconTrash); !errors.Is(err, ErrImmutableIcon) {
+		t.Errorf("got: %v want: %v", err, ErrImmutableIcon)
+	}
+
+	testMarshalJSON(t, 5, IconTrash, want)
+}
+
 func TestModifier_MarshalJSON(t *testing.T) {
 	t.Parallel()
 
@@ -345,6 +373,12 @@ func TestItem_MarshalJSON(t *testing.T) {
 			in2: NewItem("title").Mods(NewModifiers().Shift(NewModifier().Subtitle("subtitle"))),
 			out: `{"title":"title","mods":{"shift":{"subtitle":"subtitle"}}}`,
 		},
+		// With action
+		{
+			in1: &Item{title: "title", action: &Action{url: ps("https://example.com")}},
+			in2: NewItem("title").Action(NewAction().URL("https://example.com")),
+			out: `{"title":"title","action":{"url":"https://example.com"}}`,
+		},
 		// With text
 		{
 			in1: &Item{title: "title", text: &Text{copy: ps("copy")}},
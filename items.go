@@ -21,3 +21,8 @@ func (i *Items) IsEmpty() bool {
 func (i *Items) Append(items ...*Item) {
 	*i = append(*i, items...)
 }
+
+// Prepend inserts entries at the beginning of Items.
+func (i *Items) Prepend(items ...*Item) {
+	*i = append(append(make(Items, 0, len(*i)+len(items)), items...), *i...)
+}
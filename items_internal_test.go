@@ -91,3 +91,37 @@ func TestItems_Append(t *testing.T) {
 		})
 	}
 }
+
+func TestItems_Prepend(t *testing.T) {
+	t.Parallel()
+
+	first, second, third := NewItem("1"), NewItem("2"), NewItem("3")
+
+	type Test struct {
+		in  Items
+		add Items
+		out Items
+	}
+
+	tests := []Test{
+		{in: Items{}, add: Items{first}, out: Items{first}},
+		{in: Items{third}, add: Items{first, second}, out: Items{first, second, third}},
+		{in: Items{first}, add: Items{}, out: Items{first}},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Prepend", i), func(t *testing.T) {
+			t.Parallel()
+			test.in.Prepend(test.add...)
+			if test.in.Length() != test.out.Length() {
+				t.Fatalf("#%d: got: %d want: %d", i, test.in.Length(), test.out.Length())
+			}
+			for j := range test.out {
+				if test.in[j] != test.out[j] {
+					t.Errorf("#%d: [%d] got: %s want: %s", i, j, test.in[j].title, test.out[j].title)
+				}
+			}
+		})
+	}
+}
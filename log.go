@@ -0,0 +1,99 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LogFile is the file in the cache directory written by Logf when logging is enabled.
+const LogFile = "workflow.log"
+
+// logFlagFile is the file in the data directory enabling logging to LogFile.
+const logFlagFile = "logging"
+
+// LogEnabled reports whether Logf writes to LogFile, as set by SetLogEnabled
+// or the workflow:log:on and workflow:log:off magic arguments.
+func LogEnabled() bool {
+	return logEnabled(DataDir())
+}
+
+// SetLogEnabled enables or disables logging to LogFile.
+func SetLogEnabled(enabled bool) error {
+	return setLogEnabled(DataDir(), enabled)
+}
+
+// Logf writes a log line to os.Stderr, shown by Alfred's debugger, when IsDebug
+// and appends it to LogFile in the cache directory when LogEnabled.
+func Logf(format string, args ...interface{}) {
+	line := fmt.Sprintf(format, args...) + "\n"
+
+	if IsDebug() {
+		fmt.Fprint(os.Stderr, line)
+	}
+
+	if LogEnabled() {
+		_ = appendLog(CacheDir(), line)
+	}
+}
+
+func logEnabled(dataDir string) bool {
+	if dataDir == "" {
+		return false
+	}
+
+	_, err := os.Stat(filepath.Join(dataDir, logFlagFile))
+
+	return err == nil
+}
+
+func setLogEnabled(dataDir string, enabled bool) error {
+	if dataDir == "" {
+		return ErrNoWorkflowDir
+	}
+
+	path := filepath.Join(dataDir, logFlagFile)
+
+	if !enabled {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err //nolint:wrapcheck // os errors include the path
+		}
+
+		return nil
+	}
+
+	if _, err := EnsureDir(dataDir); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, nil, 0o600) //nolint:wrapcheck,gomnd // os errors include the path
+}
+
+func appendLog(cacheDir, line string) error {
+	if cacheDir == "" {
+		return ErrNoWorkflowDir
+	}
+
+	if _, err := EnsureDir(cacheDir); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(cacheDir, LogFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gomnd
+	if err != nil {
+		return err //nolint:wrapcheck // os errors include the path
+	}
+
+	if _, err := f.WriteString(time.Now().Format("2006-01-02 15:04:05 ") + line); err != nil {
+		f.Close()
+
+		return err //nolint:wrapcheck // os errors include the path
+	}
+
+	return f.Close() //nolint:wrapcheck // os errors include the path
+}
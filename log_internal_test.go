@@ -0,0 +1,61 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogf(t *testing.T) {
+	cache, data := t.TempDir(), filepath.Join(t.TempDir(), "data")
+	t.Setenv(EnvWorkflowCache, cache)
+	t.Setenv(EnvWorkflowData, data)
+	t.Setenv(EnvDebug, "")
+
+	Logf("disabled")
+
+	if _, err := os.Stat(filepath.Join(cache, LogFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got: %v want: %v", err, os.ErrNotExist)
+	}
+
+	if err := SetLogEnabled(true); err != nil || !LogEnabled() {
+		t.Fatalf("enable: got: %t, %v want: true", LogEnabled(), err)
+	}
+
+	Logf("value: %d", 1)
+	Logf("value: %d", 2)
+
+	if err := SetLogEnabled(false); err != nil || LogEnabled() {
+		t.Fatalf("disable: got: %t, %v want: false", LogEnabled(), err)
+	}
+
+	Logf("disabled")
+
+	b, err := os.ReadFile(filepath.Join(cache, LogFile))
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], " value: 1") || !strings.HasSuffix(lines[1], " value: 2") {
+		t.Errorf("got: %q", lines)
+	}
+}
+
+func TestSetLogEnabled_NoDir(t *testing.T) {
+	t.Parallel()
+
+	if err := setLogEnabled("", true); !errors.Is(err, ErrNoWorkflowDir) {
+		t.Errorf("got: %v want: %v", err, ErrNoWorkflowDir)
+	}
+
+	if logEnabled("") {
+		t.Errorf("got: enabled without a data directory")
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// magic argument defaults.
+const (
+	DefaultMagicPrefix         = "workflow:"
+	DefaultMagicUpdateTimeout  = 5 * time.Second
+	DefaultMagicInstallTimeout = time.Minute
+)
+
+// magicInstallCommand is the name of the command installing an update.
+const magicInstallCommand = "update:install"
+
+// MagicCommand is a maintenance command run by typing its magic argument.
+type MagicCommand struct {
+	name        string
+	description string
+	icon        *Icon
+	run         func() (*Item, error)
+}
+
+// NewMagicCommand returns a MagicCommand typed as prefix + name; run returns
+// the Item shown once it is done, or nil to show none.
+func NewMagicCommand(name, description string, run func() (*Item, error)) *MagicCommand {
+	return &MagicCommand{
+		name:        name,
+		description: description,
+		icon:        IconToolbarAdvanced,
+		run:         run,
+	}
+}
+
+// Icon sets the icon of the command.
+func (c *MagicCommand) Icon(icon *Icon) *MagicCommand {
+	c.icon = icon
+
+	return c
+}
+
+// MagicArgs intercepts queries starting with a prefix, "workflow:" by default,
+// before the workflow handles them.
+//
+// A partial query lists the matching commands and the full magic argument of a
+// command runs it. The built-in commands are cache (clear the cache directory),
+// reset (clear the cache and data directories), log:on and log:off (logging to
+// LogFile), update (check for an update) and update:install (install it),
+// the last two when an Updater is set.
+//
+// Alfred reruns the Script Filter as the query changes, so a command may run
+// several times and must give the same result each time.
+type MagicArgs struct {
+	prefix         string
+	cacheDir       string
+	dataDir        string
+	updater        *Updater
+	updateTimeout  time.Duration
+	installTimeout time.Duration
+	commands       []*MagicCommand
+}
+
+// NewMagicArgs returns a MagicArgs with the built-in commands.
+func NewMagicArgs() *MagicArgs {
+	return &MagicArgs{
+		prefix:         DefaultMagicPrefix,
+		cacheDir:       CacheDir(),
+		dataDir:        DataDir(),
+		updateTimeout:  DefaultMagicUpdateTimeout,
+		installTimeout: DefaultMagicInstallTimeout,
+	}
+}
+
+// Prefix sets the prefix of magic arguments; see also Updater.InstallQuery.
+func (m *MagicArgs) Prefix(prefix string) *MagicArgs {
+	m.prefix = prefix
+
+	return m
+}
+
+// CacheDir sets the directory cleared by the cache and reset commands.
+func (m *MagicArgs) CacheDir(dir string) *MagicArgs {
+	m.cacheDir = dir
+
+	return m
+}
+
+// DataDir sets the directory cleared by the reset command.
+func (m *MagicArgs) DataDir(dir string) *MagicArgs {
+	m.dataDir = dir
+
+	return m
+}
+
+// Updater sets the Updater used by the update command.
+func (m *MagicArgs) Updater(updater *Updater) *MagicArgs {
+	m.updater = updater
+
+	return m
+}
+
+// UpdateTimeout sets how long the update command waits for the release source.
+func (m *MagicArgs) UpdateTimeout(timeout time.Duration) *MagicArgs {
+	m.updateTimeout = timeout
+
+	return m
+}
+
+// InstallTimeout sets how long the update:install command waits for the
+// download of the update.
+func (m *MagicArgs) InstallTimeout(timeout time.Duration) *MagicArgs {
+	m.installTimeout = timeout
+
+	return m
+}
+
+// Add adds commands; a command replaces the built-in command of the same name.
+func (m *MagicArgs) Add(commands ...*MagicCommand) *MagicArgs {
+	m.commands = append(m.commands, commands...)
+
+	return m
+}
+
+// Commands returns the built-in and added commands in display order.
+func (m *MagicArgs) Commands() []*MagicCommand {
+	builtin := []*MagicCommand{
+		NewMagicCommand("cache", "Delete the cached data of the workflow", m.clearCache).Icon(IconTrash),
+		NewMagicCommand("reset", "Delete the cached and saved data of the workflow", m.reset).Icon(IconTrash),
+		NewMagicCommand("log:on", "Turn logging to "+LogFile+" on", m.logOn).Icon(IconGenericDocument),
+		NewMagicCommand("log:off", "Turn logging to "+LogFile+" off", m.logOff).Icon(IconGenericDocument),
+	}
+
+	if m.updater != nil {
+		builtin = append(builtin,
+			NewMagicCommand("update", "Check for a new version of the workflow", m.update).Icon(IconSync),
+			NewMagicCommand(magicInstallCommand, "Install the new version of the workflow", m.install).Icon(IconSync),
+		)
+	}
+
+	commands := make([]*MagicCommand, 0, len(builtin)+len(m.commands))
+
+	for _, c := range builtin {
+		if m.command(c.name) == nil {
+			commands = append(commands, c)
+		}
+	}
+
+	return append(commands, m.commands...)
+}
+
+func (m *MagicArgs) command(name string) *MagicCommand {
+	for _, c := range m.commands {
+		if c.name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Handle reports whether query is a magic argument, in which case the
+// resulting Items are appended to sf and the workflow should output it as is.
+func (m *MagicArgs) Handle(query string, sf *ScriptFilter) bool {
+	query = strings.TrimSpace(query)
+	if !strings.HasPrefix(query, m.prefix) {
+		return false
+	}
+
+	name := strings.TrimPrefix(query, m.prefix)
+	commands := m.Commands()
+
+	for _, c := range commands {
+		if c.name == name {
+			if item := c.exec(); item != nil {
+				sf.Items().Append(item)
+			}
+
+			return true
+		}
+	}
+
+	found := false
+
+	for _, c := range commands {
+		if strings.HasPrefix(c.name, name) {
+			found = true
+
+			sf.Items().Append(NewInvalidItem(m.prefix + c.name).
+				Subtitle(c.description).
+				Autocomplete(m.prefix + c.name).
+				Icon(c.icon))
+		}
+	}
+
+	if !found {
+		sf.Items().Append(NewInvalidItem("No matching magic argument").
+			Subtitle("Delete the query to list the commands").
+			Autocomplete(m.prefix).
+			Icon(IconAlertNote))
+	}
+
+	return true
+}
+
+func (c *MagicCommand) exec() *Item {
+	item, err := c.run()
+	if err != nil {
+		return NewInvalidItem("Error: " + c.name).Subtitle(err.Error()).Icon(IconAlertStop)
+	}
+
+	return item
+}
+
+func (m *MagicArgs) clearCache() (*Item, error) {
+	if err := clearDir(m.cacheDir); err != nil {
+		return nil, err
+	}
+
+	return NewInvalidItem("Deleted the cached data").Icon(IconTrash), nil
+}
+
+func (m *MagicArgs) reset() (*Item, error) {
+	if err := clearDir(m.cacheDir); err != nil {
+		return nil, err
+	}
+
+	if err := clearDir(m.dataDir); err != nil {
+		return nil, err
+	}
+
+	return NewInvalidItem("Deleted the cached and saved data").Icon(IconTrash), nil
+}
+
+func (m *MagicArgs) logOn() (*Item, error) {
+	if err := setLogEnabled(m.dataDir, true); err != nil {
+		return nil, err
+	}
+
+	return NewInvalidItem("Logging turned on").
+		Subtitle(filepath.Join(m.cacheDir, LogFile)).
+		Icon(IconGenericDocument), nil
+}
+
+func (m *MagicArgs) logOff() (*Item, error) {
+	if err := setLogEnabled(m.dataDir, false); err != nil {
+		return nil, err
+	}
+
+	return NewInvalidItem("Logging turned off").Icon(IconGenericDocument), nil
+}
+
+func (m *MagicArgs) update() (*Item, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.updateTimeout)
+	defer cancel()
+
+	rel, err := m.updater.Check(ctx)
+	if err != nil {
+		if errors.Is(err, ErrNoUpdate) {
+			return NewInvalidItem("The workflow is up to date").
+				Subtitle("Installed: " + m.updater.current).
+				Icon(IconSync), nil
+		}
+
+		return nil, err
+	}
+
+	return m.updater.releaseItem(rel), nil
+}
+
+func (m *MagicArgs) install() (*Item, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.installTimeout)
+	defer cancel()
+
+	if err := m.updater.Install(ctx); err != nil {
+		return nil, err
+	}
+
+	return NewInvalidItem("Installing the update").
+		Subtitle("Alfred asks to import the new version").
+		Icon(IconSync), nil
+}
+
+// clearDir deletes the contents of dir, but not dir itself.
+func clearDir(dir string) error {
+	if dir == "" {
+		return ErrNoWorkflowDir
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err //nolint:wrapcheck // os errors include the path
+	}
+
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+			return err //nolint:wrapcheck // os errors include the path
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,332 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testMagicArgs(t *testing.T) (*MagicArgs, string, string) {
+	t.Helper()
+
+	cache, data := t.TempDir(), t.TempDir()
+	writeTestFiles(t, cache, map[string]string{"a.json": "{}", "sub/b.json": "{}"})
+	writeTestFiles(t, data, map[string]string{"settings.json": "{}"})
+
+	return NewMagicArgs().CacheDir(cache).DataDir(data), cache, data
+}
+
+func testItemTitles(t *testing.T, items Items) []string {
+	t.Helper()
+
+	titles := make([]string, 0, len(items))
+	for _, item := range items {
+		titles = append(titles, item.title)
+	}
+
+	return titles
+}
+
+func testDirEntries(t *testing.T, dir string) int {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir error: %v", err)
+	}
+
+	return len(entries)
+}
+
+func TestMagicArgs_List(t *testing.T) {
+	t.Parallel()
+
+	custom := NewMagicCommand("help", "Open the help", func() (*Item, error) {
+		return NewItem("help"), nil
+	})
+
+	type Test struct {
+		query string
+		ok    bool
+		out   []string
+	}
+
+	tests := []Test{
+		{query: "query", ok: false},
+		{
+			query: "workflow:",
+			ok:    true,
+			out:   []string{"workflow:cache", "workflow:reset", "workflow:log:on", "workflow:log:off", "workflow:help"},
+		},
+		{query: "workflow:log", ok: true, out: []string{"workflow:log:on", "workflow:log:off"}},
+		{query: " workflow:re", ok: true, out: []string{"workflow:reset"}},
+		{query: "workflow:x", ok: true, out: []string{"No matching magic argument"}},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Handle", i), func(t *testing.T) {
+			t.Parallel()
+			m, _, _ := testMagicArgs(t)
+			sf := NewScriptFilter()
+			if ok := m.Add(custom).Handle(test.query, sf); ok != test.ok {
+				t.Fatalf("#%d: got: %t want: %t", i, ok, test.ok)
+			}
+			if got := testItemTitles(t, sf.items); fmt.Sprint(got) != fmt.Sprint(test.out) {
+				t.Errorf("#%d: got: %q want: %q", i, got, test.out)
+			}
+		})
+	}
+
+	m, _, _ := testMagicArgs(t)
+	sf := NewScriptFilter()
+	m.Handle("workflow:ca", sf)
+	testMarshalJSON(t, 0, sf.items[0], `{"title":"workflow:cache","subtitle":"Delete the cached data of the workflow",`+
+		`"icon":{"path":"/System/Library/CoreServices/CoreTypes.bundle/Contents/Resources/TrashIcon.icns"},`+
+		`"valid":false,"autocomplete":"workflow:cache"}`)
+}
+
+func TestMagicArgs_Cache(t *testing.T) {
+	t.Parallel()
+
+	m, cache, data := testMagicArgs(t)
+	sf := NewScriptFilter()
+
+	if !m.Handle("workflow:cache", sf) || sf.items[0].title != "Deleted the cached data" {
+		t.Errorf("got: %q", testItemTitles(t, sf.items))
+	}
+
+	if n := testDirEntries(t, cache); n != 0 {
+		t.Errorf("cache: got: %d entries want: 0", n)
+	}
+
+	if n := testDirEntries(t, data); n != 1 {
+		t.Errorf("data: got: %d entries want: 1", n)
+	}
+}
+
+func TestMagicArgs_Reset(t *testing.T) {
+	t.Parallel()
+
+	m, cache, data := testMagicArgs(t)
+	m.Handle("workflow:reset", NewScriptFilter())
+
+	if testDirEntries(t, cache)+testDirEntries(t, data) != 0 {
+		t.Errorf("got: remaining files in %s or %s", cache, data)
+	}
+
+	sf := NewScriptFilter()
+	NewMagicArgs().CacheDir("").Handle("workflow:reset", sf)
+	testMarshalJSON(t, 0, sf.items[0], `{"title":"Error: reset","subtitle":"workflow directory is not set",`+
+		`"icon":{"path":"/System/Library/CoreServices/CoreTypes.bundle/Contents/Resources/AlertStopIcon.icns"},"valid":false}`)
+}
+
+func TestMagicArgs_Log(t *testing.T) {
+	t.Parallel()
+
+	m, _, data := testMagicArgs(t)
+
+	type Test struct {
+		query   string
+		out     string
+		enabled bool
+	}
+
+	// Alfred reruns the Script Filter with the same query, which must not flip the setting.
+	tests := []Test{
+		{query: "workflow:log:on", out: "Logging turned on", enabled: true},
+		{query: "workflow:log:on", out: "Logging turned on", enabled: true},
+		{query: "workflow:log:off", out: "Logging turned off", enabled: false},
+		{query: "workflow:log:off", out: "Logging turned off", enabled: false},
+	}
+
+	for i, test := range tests {
+		sf := NewScriptFilter()
+		m.Handle(test.query, sf)
+
+		if sf.items[0].title != test.out {
+			t.Errorf("#%d: got: %s want: %s", i, sf.items[0].title, test.out)
+		}
+
+		if enabled := logEnabled(data); enabled != test.enabled {
+			t.Errorf("#%d: enabled: got: %t want: %t", i, enabled, test.enabled)
+		}
+	}
+}
+
+func TestMagicArgs_Update(t *testing.T) {
+	t.Parallel()
+
+	s := newTestReleaseServer(t)
+
+	type Test struct {
+		current string
+		out     string
+	}
+
+	tests := []Test{
+		{current: "1.0.0", out: "Update available: v1.2.0"},
+		{current: "1.2.0", out: "The workflow is up to date"},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Update", i), func(t *testing.T) {
+			t.Parallel()
+			m, cache, _ := testMagicArgs(t)
+			m.Updater(NewUpdater(s.source(), test.current).CacheDir(cache))
+			sf := NewScriptFilter()
+			m.Handle("workflow:update", sf)
+			if got := sf.items[0].title; got != test.out {
+				t.Errorf("#%d: got: %s want: %s", i, got, test.out)
+			}
+		})
+	}
+}
+
+func TestMagicArgs_UpdateNoCacheDir(t *testing.T) {
+	t.Parallel()
+
+	s := newTestReleaseServer(t)
+	m, _, _ := testMagicArgs(t)
+	m.Updater(NewUpdater(s.source(), "1.0.0").CacheDir(""))
+
+	sf := NewScriptFilter()
+	m.Handle("workflow:update", sf)
+
+	if len(sf.items) != 1 || sf.items[0] == nil {
+		t.Fatalf("got: %v want: one item", sf.items)
+	}
+
+	if got := sf.items[0].title; got != "Update available: v1.2.0" {
+		t.Errorf("got: %s want: %s", got, "Update available: v1.2.0")
+	}
+}
+
+func TestMagicArgs_NilItem(t *testing.T) {
+	t.Parallel()
+
+	m, _, _ := testMagicArgs(t)
+	m.Add(NewMagicCommand("quiet", "Show nothing", func() (*Item, error) {
+		return nil, nil //nolint:nilnil // no item to show
+	}))
+
+	sf := NewScriptFilter()
+	if !m.Handle("workflow:quiet", sf) {
+		t.Fatal("got: not handled want: handled")
+	}
+
+	if len(sf.items) != 0 {
+		t.Errorf("got: %d items want: 0", len(sf.items))
+	}
+}
+
+func TestMagicArgs_UpdateInstall(t *testing.T) {
+	t.Parallel()
+
+	s := newTestReleaseServer(t)
+	m, cache, _ := testMagicArgs(t)
+	u := NewUpdater(s.source(), "1.0.0").CacheDir(cache).Client(s.Client())
+	m.Updater(u)
+
+	var opened string
+
+	u.open = func(path string) error {
+		opened = path
+
+		return nil
+	}
+
+	sf := NewScriptFilter()
+	m.Handle("workflow:update", sf)
+
+	query := sf.items[0].autocomplete
+	if query == nil || *query != "workflow:update:install" {
+		t.Fatalf("got: %v want: %s", query, "workflow:update:install")
+	}
+
+	sf = NewScriptFilter()
+	m.Handle(*query, sf)
+
+	if got := sf.items[0].title; got != "Installing the update" {
+		t.Errorf("got: %s want: %s", got, "Installing the update")
+	}
+
+	if filepath.Base(opened) != "Test-1.2.0.alfredworkflow" {
+		t.Errorf("got: %s want: Test-1.2.0.alfredworkflow", opened)
+	}
+}
+
+func TestMagicArgs_UpdateTimeout(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+
+	t.Cleanup(func() {
+		close(release)
+		s.Close()
+	})
+
+	m, cache, _ := testMagicArgs(t)
+	source := NewGitHubReleases("owner", "repo").BaseURL(s.URL).Client(s.Client())
+	m.Updater(NewUpdater(source, "1.0.0").CacheDir(cache)).UpdateTimeout(50 * time.Millisecond)
+
+	done := make(chan *ScriptFilter)
+
+	go func() {
+		sf := NewScriptFilter()
+		m.Handle("workflow:update", sf)
+		done <- sf
+	}()
+
+	select {
+	case sf := <-done:
+		if got := sf.items[0].title; got != "Error: update" {
+			t.Errorf("got: %s want: %s", got, "Error: update")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("got: no result want: timeout error")
+	}
+}
+
+func TestMagicArgs_Override(t *testing.T) {
+	t.Parallel()
+
+	m, cache, _ := testMagicArgs(t)
+	m.Add(NewMagicCommand("cache", "Custom", func() (*Item, error) {
+		return nil, errors.New("not allowed") //nolint:goerr113 // test
+	}))
+
+	sf := NewScriptFilter()
+	m.Handle("workflow:cache", sf)
+
+	if n := testDirEntries(t, cache); n != 2 {
+		t.Errorf("cache: got: %d entries want: 2", n)
+	}
+
+	out, err := json.Marshal(sf.items)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+
+	if want := `"title":"Error: cache","subtitle":"not allowed"`; !strings.Contains(string(out), want) {
+		t.Errorf("got: %s want: %s", out, want)
+	}
+}
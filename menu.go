@@ -0,0 +1,276 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultMenuSeparator separates the levels of a Menu in the query.
+const DefaultMenuSeparator = " › "
+
+// Menu is a node of a tree of choices, such as project → environment →
+// service, browsed level by level in a Script Filter.
+//
+// The query holds the path to the current level, the titles of the levels
+// joined by the separator, followed by text filtering the current level.
+// Items drill down with autocomplete and leaves are valid items whose arg is
+// the path, which Selected turns back into the selection.
+type Menu struct {
+	title     string
+	subtitle  string
+	icon      *Icon
+	value     string
+	children  []*Menu
+	separator string
+	handler   func(sel *MenuSelection, sf *ScriptFilter)
+}
+
+// MenuSelection is a selected leaf of a Menu.
+type MenuSelection struct {
+	// Titles holds the titles of the levels from the first one down to the leaf.
+	Titles []string
+	// Value is the value of the leaf.
+	Value string
+	// Query is the text typed after the leaf, when rendered by a handler.
+	Query string
+}
+
+// NewMenu returns a Menu with the given title.
+func NewMenu(title string) *Menu {
+	return &Menu{title: title, separator: DefaultMenuSeparator}
+}
+
+// Subtitle sets the subtitle shown after the breadcrumbs.
+func (m *Menu) Subtitle(subtitle string) *Menu {
+	m.subtitle = subtitle
+
+	return m
+}
+
+// Icon sets the icon.
+func (m *Menu) Icon(icon *Icon) *Menu {
+	m.icon = icon
+
+	return m
+}
+
+// Value sets a value of the menu for the handler, such as an identifier.
+func (m *Menu) Value(value string) *Menu {
+	m.value = value
+
+	return m
+}
+
+// Add appends children to the Menu.
+func (m *Menu) Add(children ...*Menu) *Menu {
+	m.children = append(m.children, children...)
+
+	return m
+}
+
+// Separator sets the separator of the levels in the query. It is used on the root menu.
+func (m *Menu) Separator(separator string) *Menu {
+	m.separator = separator
+
+	return m
+}
+
+// Handler sets the function rendering the items of a selected leaf, such as
+// actions on a service. It is used on the root menu.
+//
+// With a handler, leaves drill down like other menus instead of being valid.
+func (m *Menu) Handler(handler func(sel *MenuSelection, sf *ScriptFilter)) *Menu {
+	m.handler = handler
+
+	return m
+}
+
+// IsLeaf reports whether the Menu has no children.
+func (m *Menu) IsLeaf() bool {
+	return len(m.children) == 0
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+//
+// A menu is an object with the keys title, subtitle, icon, value and children.
+// The icon may be a system icon name, a path or an icon object.
+func (m *Menu) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Title    string  `json:"title"`
+		Subtitle string  `json:"subtitle"`
+		Icon     *Icon   `json:"icon"`
+		Value    string  `json:"value"`
+		Children []*Menu `json:"children"`
+	}
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("menu: %w", err)
+	}
+
+	*m = Menu{
+		title:     v.Title,
+		subtitle:  v.Subtitle,
+		icon:      v.Icon,
+		value:     v.Value,
+		children:  v.Children,
+		separator: DefaultMenuSeparator,
+	}
+
+	return nil
+}
+
+// Render appends to sf the items of the level named by query: a Back item
+// below the first level, then the children whose title contains the filter.
+// A leaf named by query is rendered by the handler.
+func (m *Menu) Render(query string, sf *ScriptFilter) {
+	path, filter := m.resolve(query)
+	current := m
+
+	if len(path) > 0 {
+		current = path[len(path)-1]
+	}
+
+	if current.IsLeaf() && len(path) > 0 && m.handler != nil {
+		m.handler(m.selection(path, filter), sf)
+
+		return
+	}
+
+	if len(path) > 0 {
+		sf.Items().Append(NewInvalidItem("Back").
+			Subtitle(m.breadcrumbs(path[:len(path)-1])).
+			Autocomplete(m.query(path[:len(path)-1])).
+			Icon(IconBackwardArrow))
+	}
+
+	found := false
+	lower := strings.ToLower(strings.TrimSpace(filter))
+
+	for _, child := range current.children {
+		if !strings.Contains(strings.ToLower(child.title), lower) {
+			continue
+		}
+
+		found = true
+
+		sf.Items().Append(m.item(append(append([]*Menu(nil), path...), child)))
+	}
+
+	if !found {
+		sf.Items().Append(NewInvalidItem("No matching items").
+			Subtitle(m.breadcrumbs(path)).
+			Autocomplete(m.query(path)).
+			Icon(IconAlertNote))
+	}
+}
+
+// Selected returns the leaf whose path is arg, the arg of a leaf item.
+func (m *Menu) Selected(arg string) (*MenuSelection, bool) {
+	path, filter := m.resolve(arg + m.separator)
+	if len(path) == 0 || filter != "" || !path[len(path)-1].IsLeaf() {
+		return nil, false
+	}
+
+	return m.selection(path, ""), true
+}
+
+func (m *Menu) selection(path []*Menu, query string) *MenuSelection {
+	sel := &MenuSelection{Value: path[len(path)-1].value, Query: query}
+	for _, p := range path {
+		sel.Titles = append(sel.Titles, p.title)
+	}
+
+	return sel
+}
+
+func (m *Menu) item(path []*Menu) *Item {
+	child := path[len(path)-1]
+	full := m.join(path)
+
+	subtitle := m.breadcrumbs(path[:len(path)-1])
+	if child.subtitle != "" {
+		subtitle += " · " + child.subtitle
+	}
+
+	icon := child.icon
+	if icon == nil && !child.IsLeaf() {
+		icon = IconGenericFolder
+	}
+
+	if child.IsLeaf() && m.handler == nil {
+		return NewItem(child.title).
+			UID(full).
+			Subtitle(subtitle).
+			Arg(full).
+			Autocomplete(full).
+			Icon(icon)
+	}
+
+	return NewInvalidItem(child.title).
+		UID(full).
+		Subtitle(subtitle).
+		Autocomplete(full + m.separator).
+		Icon(icon)
+}
+
+// resolve returns the menus named by the complete levels of query and the
+// remaining text.
+func (m *Menu) resolve(query string) ([]*Menu, string) {
+	parts := strings.Split(query, m.separator)
+	current := m
+
+	var path []*Menu
+
+	for i, part := range parts[:len(parts)-1] {
+		next := current.child(part)
+		if next == nil {
+			return path, strings.Join(parts[i:], m.separator)
+		}
+
+		path = append(path, next)
+		current = next
+	}
+
+	return path, parts[len(parts)-1]
+}
+
+func (m *Menu) child(title string) *Menu {
+	for _, c := range m.children {
+		if strings.EqualFold(c.title, strings.TrimSpace(title)) {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func (m *Menu) join(path []*Menu) string {
+	titles := make([]string, len(path))
+	for i, p := range path {
+		titles[i] = p.title
+	}
+
+	return strings.Join(titles, m.separator)
+}
+
+// query returns the query of the level of path.
+func (m *Menu) query(path []*Menu) string {
+	if len(path) == 0 {
+		return ""
+	}
+
+	return m.join(path) + m.separator
+}
+
+func (m *Menu) breadcrumbs(path []*Menu) string {
+	if m.title == "" {
+		return m.join(path)
+	}
+
+	return m.join(append([]*Menu{m}, path...))
+}
@@ -0,0 +1,180 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func testMenu() *Menu {
+	return NewMenu("Deploy").Add(
+		NewMenu("api").Add(
+			NewMenu("staging").Add(
+				NewMenu("web").Value("svc-1"),
+				NewMenu("worker").Value("svc-2").Subtitle("queue"),
+			),
+			NewMenu("production"),
+		),
+		NewMenu("site"),
+	)
+}
+
+func testItemsJSON(t *testing.T, items Items) string {
+	t.Helper()
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+
+	return string(data)
+}
+
+func TestMenu_Render(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		query string
+		out   Items
+	}
+
+	tests := []Test{
+		{
+			query: "",
+			out: Items{
+				NewInvalidItem("api").UID("api").Subtitle("Deploy").Autocomplete("api › ").Icon(IconGenericFolder),
+				NewItem("site").UID("site").Subtitle("Deploy").Arg("site").Autocomplete("site"),
+			},
+		},
+		{
+			query: "api › ",
+			out: Items{
+				NewInvalidItem("Back").Subtitle("Deploy").Autocomplete("").Icon(IconBackwardArrow),
+				NewInvalidItem("staging").UID("api › staging").Subtitle("Deploy › api").
+					Autocomplete("api › staging › ").Icon(IconGenericFolder),
+				NewItem("production").UID("api › production").Subtitle("Deploy › api").
+					Arg("api › production").Autocomplete("api › production"),
+			},
+		},
+		{
+			query: "API › staging › WO",
+			out: Items{
+				NewInvalidItem("Back").Subtitle("Deploy › api").Autocomplete("api › ").Icon(IconBackwardArrow),
+				NewItem("worker").UID("api › staging › worker").Subtitle("Deploy › api › staging · queue").
+					Arg("api › staging › worker").Autocomplete("api › staging › worker"),
+			},
+		},
+		{
+			query: "nope › x",
+			out: Items{
+				NewInvalidItem("No matching items").Subtitle("Deploy").Autocomplete("").Icon(IconAlertNote),
+			},
+		},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Render", i), func(t *testing.T) {
+			t.Parallel()
+
+			sf := NewScriptFilter()
+			testMenu().Render(test.query, sf)
+
+			testMarshalJSON(t, i, *sf.Items(), testItemsJSON(t, test.out))
+		})
+	}
+}
+
+func TestMenu_Handler(t *testing.T) {
+	t.Parallel()
+
+	var got *MenuSelection
+
+	menu := testMenu().Handler(func(sel *MenuSelection, sf *ScriptFilter) {
+		got = sel
+
+		sf.Items().Append(NewItem("restart " + sel.Value))
+	})
+
+	sf := NewScriptFilter()
+	menu.Render("", sf)
+	testMarshalJSON(t, 0, (*sf.Items())[1],
+		`{"uid":"site","title":"site","subtitle":"Deploy","valid":false,"autocomplete":"site › "}`)
+
+	sf = NewScriptFilter()
+	menu.Render("api › staging › web › re", sf)
+
+	want := &MenuSelection{Titles: []string{"api", "staging", "web"}, Value: "svc-1", Query: "re"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %+v want: %+v", got, want)
+	}
+
+	if titles := testItemTitles(t, *sf.Items()); !reflect.DeepEqual(titles, []string{"restart svc-1"}) {
+		t.Errorf("got: %q want: %q", titles, []string{"restart svc-1"})
+	}
+}
+
+func TestMenu_Selected(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		arg string
+		out *MenuSelection
+	}
+
+	tests := []Test{
+		{arg: "api › staging › worker", out: &MenuSelection{Titles: []string{"api", "staging", "worker"}, Value: "svc-2"}},
+		{arg: "site", out: &MenuSelection{Titles: []string{"site"}}},
+		{arg: "api › staging", out: nil},
+		{arg: "api › unknown", out: nil},
+		{arg: "", out: nil},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Selected", i), func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := testMenu().Selected(test.arg)
+			if ok != (test.out != nil) || !reflect.DeepEqual(got, test.out) {
+				t.Errorf("#%d: got: %+v, %v want: %+v", i, got, ok, test.out)
+			}
+		})
+	}
+}
+
+func TestMenu_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	data := `{"title":"Deploy","children":[
+		{"title":"api","icon":"folder","children":[{"title":"web","value":"svc-1","icon":"./web.png"}]}
+	]}`
+
+	var menu Menu
+	if err := json.Unmarshal([]byte(data), &menu); err != nil {
+		t.Fatal(err)
+	}
+
+	sf := NewScriptFilter()
+	menu.Render("api › ", sf)
+
+	want := Items{
+		NewInvalidItem("Back").Subtitle("Deploy").Autocomplete("").Icon(IconBackwardArrow),
+		NewItem("web").UID("api › web").Subtitle("Deploy › api").Arg("api › web").Autocomplete("api › web").
+			Icon(NewIcon("./web.png")),
+	}
+	testMarshalJSON(t, 0, *sf.Items(), testItemsJSON(t, want))
+
+	if sel, ok := menu.Selected("api › web"); !ok || sel.Value != "svc-1" {
+		t.Errorf("got: %+v want: value %q", sel, "svc-1")
+	}
+
+	if err := json.Unmarshal([]byte(`{"title":1}`), &menu); err == nil {
+		t.Error("got: nil want: error")
+	}
+}
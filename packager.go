@@ -0,0 +1,392 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"archive/zip"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WorkflowArchiveExt is the file extension of a distributable workflow archive.
+const WorkflowArchiveExt = ".alfredworkflow"
+
+// DefaultIgnoreFile is the name of the file listing paths excluded from a workflow archive.
+const DefaultIgnoreFile = ".alfredignore"
+
+const (
+	archiveFilePerm = 0o644
+	archiveExecPerm = 0o755
+	archiveDirPerm  = 0o755
+)
+
+// ErrNoWorkflowName is returned when info.plist does not define the workflow name.
+var ErrNoWorkflowName = errors.New("info.plist has no workflow name")
+
+// defaultIgnorePatterns returns the patterns always excluded from a workflow archive.
+func defaultIgnorePatterns() []string {
+	return []string{
+		".git/",
+		".DS_Store",
+		"prefs.plist",
+		"*" + WorkflowArchiveExt,
+		"*" + WorkflowArchiveExt + ".sha256",
+	}
+}
+
+// PackageResult describes a workflow archive written by Packager.
+type PackageResult struct {
+	// Path is the path of the .alfredworkflow archive.
+	Path string
+	// ChecksumPath is the path of the file holding the archive's SHA-256 checksum.
+	ChecksumPath string
+	// Checksum is the hex encoded SHA-256 checksum of the archive.
+	Checksum string
+	// Files are the slash separated paths stored in the archive.
+	Files []string
+}
+
+// Packager builds a distributable .alfredworkflow archive from a workflow directory.
+//
+// The archive is reproducible: entries are sorted, permissions are normalized to
+// 0644 (0755 for executables) and every entry has the same modification time.
+type Packager struct {
+	dir        string
+	outputDir  string
+	ignoreFile string
+	modTime    time.Time
+}
+
+// NewPackager returns a Packager for the workflow in dir.
+func NewPackager(dir string) *Packager {
+	return &Packager{
+		dir:        dir,
+		outputDir:  dir,
+		ignoreFile: DefaultIgnoreFile,
+		modTime:    time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// OutputDir sets the directory the archive and checksum are written to.
+func (p *Packager) OutputDir(dir string) *Packager {
+	p.outputDir = dir
+
+	return p
+}
+
+// IgnoreFile sets the name of the ignore file, relative to the workflow directory.
+func (p *Packager) IgnoreFile(name string) *Packager {
+	p.ignoreFile = name
+
+	return p
+}
+
+// ModTime sets the modification time stored for every archive entry.
+func (p *Packager) ModTime(t time.Time) *Packager {
+	p.modTime = t
+
+	return p
+}
+
+// Package writes the workflow archive and its checksum file.
+func (p *Packager) Package() (*PackageResult, error) {
+	name, version, err := p.readInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	ignore, err := p.readIgnore()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := p.collect(ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	archivePath := filepath.Join(p.outputDir, archiveName(name, version))
+
+	checksum, err := p.writeArchive(archivePath, files)
+	if err != nil {
+		return nil, err
+	}
+
+	checksumPath := archivePath + ".sha256"
+	line := checksum + "  " + filepath.Base(archivePath) + "\n"
+
+	if err := os.WriteFile(checksumPath, []byte(line), archiveFilePerm); err != nil {
+		return nil, fmt.Errorf("write checksum: %w", err)
+	}
+
+	return &PackageResult{
+		Path:         archivePath,
+		ChecksumPath: checksumPath,
+		Checksum:     checksum,
+		Files:        files,
+	}, nil
+}
+
+func (p *Packager) readInfo() (string, string, error) {
+	info, err := ReadWorkflowInfo(filepath.Join(p.dir, "info.plist"))
+	if err != nil {
+		return "", "", err
+	}
+
+	if info.Name == "" {
+		return "", "", ErrNoWorkflowName
+	}
+
+	return info.Name, info.Version, nil
+}
+
+func (p *Packager) readIgnore() (*ignoreList, error) {
+	ignore := newIgnoreList(defaultIgnorePatterns())
+
+	if p.ignoreFile == "" {
+		return ignore, nil
+	}
+
+	ignore.add(filepath.ToSlash(p.ignoreFile))
+
+	f, err := os.Open(filepath.Join(p.dir, p.ignoreFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ignore, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("open ignore file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		ignore.add(scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read ignore file: %w", err)
+	}
+
+	return ignore, nil
+}
+
+func (p *Packager) collect(ignore *ignoreList) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(p.dir, func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(p.dir, fpath)
+		if err != nil {
+			return err
+		}
+
+		if rel == "." {
+			return nil
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if ignore.match(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.IsDir() {
+			files = append(files, rel)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk workflow directory: %w", err)
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func (p *Packager) writeArchive(archivePath string, files []string) (string, error) {
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("create archive: %w", err)
+	}
+
+	hash := sha256.New()
+	zw := zip.NewWriter(io.MultiWriter(out, hash))
+	dirs := make(map[string]bool)
+
+	for _, name := range files {
+		if err := p.writeDirs(zw, dirs, path.Dir(name)); err != nil {
+			_ = out.Close()
+
+			return "", err
+		}
+
+		if err := p.writeFile(zw, name); err != nil {
+			_ = out.Close()
+
+			return "", err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		_ = out.Close()
+
+		return "", fmt.Errorf("finish archive: %w", err)
+	}
+
+	if err := out.Close(); err != nil {
+		return "", fmt.Errorf("close archive: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (p *Packager) writeDirs(zw *zip.Writer, written map[string]bool, dir string) error {
+	if dir == "." || written[dir] {
+		return nil
+	}
+
+	if err := p.writeDirs(zw, written, path.Dir(dir)); err != nil {
+		return err
+	}
+
+	header := &zip.FileHeader{Name: dir + "/", Modified: p.modTime}
+	header.SetMode(fs.ModeDir | archiveDirPerm)
+
+	if _, err := zw.CreateHeader(header); err != nil {
+		return fmt.Errorf("add %s: %w", dir, err)
+	}
+
+	written[dir] = true
+
+	return nil
+}
+
+func (p *Packager) writeFile(zw *zip.Writer, name string) error {
+	f, err := os.Open(filepath.Join(p.dir, filepath.FromSlash(name)))
+	if err != nil {
+		return fmt.Errorf("open %s: %w", name, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", name, err)
+	}
+
+	var perm fs.FileMode = archiveFilePerm
+	if info.Mode()&0o111 != 0 {
+		perm = archiveExecPerm
+	}
+
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: p.modTime}
+	header.SetMode(perm)
+
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("add %s: %w", name, err)
+	}
+
+	if _, err := io.Copy(w, f); err != nil {
+		return fmt.Errorf("add %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// archiveName returns the archive file name for the workflow name and version.
+func archiveName(name, version string) string {
+	base := strings.Join(strings.Fields(name), "-")
+	base = strings.NewReplacer("/", "-", ":", "-").Replace(base)
+
+	if version != "" {
+		base += "-" + version
+	}
+
+	return base + WorkflowArchiveExt
+}
+
+// ignoreList matches slash separated paths against gitignore like patterns.
+//
+// A pattern without a slash matches the base name of any path, a pattern
+// containing a slash is matched against the whole path relative to the
+// workflow directory and a trailing slash matches directories only.
+type ignoreList struct {
+	patterns []ignorePattern
+}
+
+type ignorePattern struct {
+	glob     string
+	dirOnly  bool
+	anchored bool
+}
+
+func newIgnoreList(patterns []string) *ignoreList {
+	l := &ignoreList{}
+	for _, pattern := range patterns {
+		l.add(pattern)
+	}
+
+	return l
+}
+
+func (l *ignoreList) add(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	p := ignorePattern{}
+
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+
+	if strings.Contains(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+
+	p.glob = line
+	l.patterns = append(l.patterns, p)
+}
+
+func (l *ignoreList) match(name string, isDir bool) bool {
+	for _, p := range l.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+
+		target := name
+		if !p.anchored {
+			target = path.Base(name)
+		}
+
+		if ok, _ := path.Match(p.glob, target); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,237 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>bundleid</key>
+	<string>com.example.test</string>
+	<key>connections</key>
+	<dict>
+		<key>name</key>
+		<string>nested</string>
+	</dict>
+	<key>disabled</key>
+	<false/>
+	<key>name</key>
+	<string>Test Workflow</string>
+	<key>version</key>
+	<string>1.2.0</string>
+</dict>
+</plist>
+`
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		fpath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fpath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		perm := os.FileMode(0o600)
+		if name == "workflow" {
+			perm = 0o700
+		}
+
+		if err := os.WriteFile(fpath, []byte(content), perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestArchiveName(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		name    string
+		version string
+		out     string
+	}
+
+	tests := []Test{
+		{name: "Test", version: "", out: "Test.alfredworkflow"},
+		{name: "Test Workflow", version: "1.0.0", out: "Test-Workflow-1.0.0.alfredworkflow"},
+		{name: "a/b: c", version: "2", out: "a-b--c-2.alfredworkflow"},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:archiveName", i), func(t *testing.T) {
+			t.Parallel()
+			if got := archiveName(test.name, test.version); got != test.out {
+				t.Errorf("#%d: got: %s want: %s", i, got, test.out)
+			}
+		})
+	}
+}
+
+func TestIgnoreList_Match(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		name  string
+		isDir bool
+		out   bool
+	}
+
+	list := newIgnoreList([]string{"# comment", "", "*.go", "build/", "/docs/*.md", "vendor"})
+
+	tests := []Test{
+		{name: "main.go", out: true},
+		{name: "pkg/util.go", out: true},
+		{name: "build", isDir: true, out: true},
+		{name: "build", isDir: false, out: false},
+		{name: "docs/readme.md", out: true},
+		{name: "other/docs/readme.md", out: false},
+		{name: "src/vendor", isDir: true, out: true},
+		{name: "icon.png", out: false},
+		{name: "# comment", out: false},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:match", i), func(t *testing.T) {
+			t.Parallel()
+			if got := list.match(test.name, test.isDir); got != test.out {
+				t.Errorf("#%d: got: %t want: %t", i, got, test.out)
+			}
+		})
+	}
+}
+
+func TestPackager_Package(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"info.plist":             testInfoPlist,
+		"workflow":               "binary",
+		"icon.png":               "png",
+		"main.go":                "package main",
+		"assets/images/logo.png": "logo",
+		".alfredignore":          "*.go\n",
+		".git/HEAD":              "ref",
+		"prefs.plist":            "prefs",
+		"Old-1.0.alfredworkflow": "old",
+	})
+
+	out := t.TempDir()
+	modTime := time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	result, err := NewPackager(dir).OutputDir(out).ModTime(modTime).Package()
+	if err != nil {
+		t.Fatalf("Package error: %v", err)
+	}
+
+	if want := filepath.Join(out, "Test-Workflow-1.2.0.alfredworkflow"); result.Path != want {
+		t.Errorf("path: got: %s want: %s", result.Path, want)
+	}
+
+	wantFiles := []string{"assets/images/logo.png", "icon.png", "info.plist", "workflow"}
+	if !reflect.DeepEqual(result.Files, wantFiles) {
+		t.Errorf("files: got: %v want: %v", result.Files, wantFiles)
+	}
+
+	data, err := os.ReadFile(result.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256(data)
+	if result.Checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("checksum: got: %s want: %s", result.Checksum, hex.EncodeToString(sum[:]))
+	}
+
+	checksum, err := os.ReadFile(result.ChecksumPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := result.Checksum + "  Test-Workflow-1.2.0.alfredworkflow\n"; string(checksum) != want {
+		t.Errorf("checksum file: got: %q want: %q", checksum, want)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantModes := map[string]os.FileMode{
+		"assets/":                os.ModeDir | 0o755,
+		"assets/images/":         os.ModeDir | 0o755,
+		"assets/images/logo.png": 0o644,
+		"icon.png":               0o644,
+		"info.plist":             0o644,
+		"workflow":               0o755,
+	}
+
+	if len(zr.File) != len(wantModes) {
+		t.Errorf("entries: got: %d want: %d", len(zr.File), len(wantModes))
+	}
+
+	for _, f := range zr.File {
+		if mode, ok := wantModes[f.Name]; !ok || f.Mode() != mode {
+			t.Errorf("%s: got mode: %v want: %v", f.Name, f.Mode(), mode)
+		}
+
+		if !f.Modified.Equal(modTime) {
+			t.Errorf("%s: got modified: %v want: %v", f.Name, f.Modified, modTime)
+		}
+	}
+}
+
+func TestPackager_Reproducible(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"info.plist": testInfoPlist, "workflow": "binary"})
+
+	first, err := NewPackager(dir).OutputDir(t.TempDir()).Package()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chtimes(filepath.Join(dir, "workflow"), time.Now(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := NewPackager(dir).OutputDir(t.TempDir()).Package()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Checksum != second.Checksum {
+		t.Errorf("got different checksums: %s and %s", first.Checksum, second.Checksum)
+	}
+}
+
+func TestPackager_NoName(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"info.plist": `<plist><dict></dict></plist>`})
+
+	if _, err := NewPackager(dir).Package(); !errors.Is(err, ErrNoWorkflowName) {
+		t.Errorf("got: %v want: %v", err, ErrNoWorkflowName)
+	}
+}
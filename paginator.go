@@ -0,0 +1,206 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// pagination defaults.
+const (
+	DefaultPageSize       = 50
+	DefaultPageToken      = "page:"
+	DefaultCursorVariable = "page_cursor"
+)
+
+// Paginator shows large result sets a page at a time, as Alfred becomes slow
+// with thousands of items.
+//
+// The page is given by a token at the end of the query, such as "term page:2".
+// The last item of a page autocompletes to the query of the next page, which
+// reruns the Script Filter.
+type Paginator struct {
+	size  int
+	token string
+}
+
+// NewPaginator returns a Paginator with the default page size and token.
+func NewPaginator() *Paginator {
+	return &Paginator{size: DefaultPageSize, token: DefaultPageToken}
+}
+
+// PageSize sets the number of items of a page.
+func (p *Paginator) PageSize(size int) *Paginator {
+	p.size = size
+
+	return p
+}
+
+// Token sets the token preceding the page number in the query.
+func (p *Paginator) Token(token string) *Paginator {
+	p.token = token
+
+	return p
+}
+
+// Parse returns the query without the page token and the page number, from 1.
+func (p *Paginator) Parse(query string) (string, int) {
+	return parsePageQuery(query, p.token)
+}
+
+// Page returns the items of the page of query followed, unless it is the last
+// page, by an item showing the next page.
+func (p *Paginator) Page(query string, items Items) Items {
+	base, page := p.Parse(query)
+	size := maxInt(p.size, 1)
+
+	// The page comes from the query, so clamp it before multiplying.
+	start := len(items)
+	if page-1 < (len(items)+size-1)/size {
+		start = (page - 1) * size
+	}
+
+	end := start + size
+	if end > len(items) {
+		end = len(items)
+	}
+
+	out := append(make(Items, 0, end-start+1), items[start:end]...)
+
+	if remaining := len(items) - end; remaining > 0 {
+		out = append(out, p.MoreItem(base, page+1, remaining))
+	}
+
+	return out
+}
+
+// MoreItem returns the item showing the page of base, with the number of
+// remaining items, for results paged by an API.
+func (p *Paginator) MoreItem(base string, page, remaining int) *Item {
+	return moreItem(fmt.Sprintf("Show more (%d remaining)", remaining), pageQuery(base, p.token, page), page)
+}
+
+// CursorPaginator is a Paginator for APIs paging with opaque continuation
+// tokens, or cursors.
+//
+// The cursor of the next page is kept, with the query it belongs to, in a
+// variable of the ScriptFilter (see ScriptFilter.Variables).
+type CursorPaginator struct {
+	token    string
+	variable string
+}
+
+type cursorState struct {
+	Query  string `json:"query"`
+	Page   int    `json:"page"`
+	Cursor string `json:"cursor"`
+}
+
+// NewCursorPaginator returns a CursorPaginator with the default token and variable.
+func NewCursorPaginator() *CursorPaginator {
+	return &CursorPaginator{token: DefaultPageToken, variable: DefaultCursorVariable}
+}
+
+// Token sets the token preceding the page number in the query.
+func (c *CursorPaginator) Token(token string) *CursorPaginator {
+	c.token = token
+
+	return c
+}
+
+// Variable sets the name of the variable holding the cursor.
+func (c *CursorPaginator) Variable(variable string) *CursorPaginator {
+	c.variable = variable
+
+	return c
+}
+
+// Cursor returns the query without the page token and the cursor of its page,
+// empty for the first page or when the cursor is unknown.
+func (c *CursorPaginator) Cursor(query string) (string, string) {
+	return c.cursor(query, os.LookupEnv)
+}
+
+func (c *CursorPaginator) cursor(query string, lookup func(string) (string, bool)) (string, string) {
+	base, page := parsePageQuery(query, c.token)
+	if page == 1 {
+		return base, ""
+	}
+
+	v, ok := lookup(c.variable)
+	if !ok {
+		return base, ""
+	}
+
+	var state cursorState
+	if err := json.Unmarshal([]byte(v), &state); err != nil || state.Query != base || state.Page != page {
+		return base, ""
+	}
+
+	return base, state.Cursor
+}
+
+// More stores next, the cursor of the page after the page of query, in the
+// variables of sf and returns the item showing that page. It returns nil when
+// next is empty, on the last page.
+func (c *CursorPaginator) More(sf *ScriptFilter, query, next string) *Item {
+	if next == "" {
+		return nil
+	}
+
+	base, page := parsePageQuery(query, c.token)
+
+	data, err := json.Marshal(cursorState{Query: base, Page: page + 1, Cursor: next})
+	if err != nil {
+		return nil
+	}
+
+	sf.Variables().Put(c.variable, string(data))
+
+	return moreItem("Show more", pageQuery(base, c.token, page+1), page+1)
+}
+
+func moreItem(title, query string, page int) *Item {
+	return NewInvalidItem(title).
+		Subtitle(fmt.Sprintf("Press ↩ to show page %d", page)).
+		Autocomplete(query).
+		Icon(IconForwardArrow)
+}
+
+// maxPage caps the page number read from a query, which keeps the number of
+// the next page from overflowing.
+const maxPage = math.MaxInt32
+
+func parsePageQuery(query, token string) (string, int) {
+	i := strings.LastIndex(query, token)
+	if i < 0 || (i > 0 && query[i-1] != ' ') {
+		return query, 1
+	}
+
+	page, err := strconv.ParseUint(query[i+len(token):], 10, 64)
+	if (err != nil && !errors.Is(err, strconv.ErrRange)) || page < 1 {
+		return query, 1
+	}
+
+	if page > maxPage {
+		page = maxPage
+	}
+
+	return strings.TrimSuffix(query[:i], " "), int(page)
+}
+
+func pageQuery(base, token string, page int) string {
+	if base == "" {
+		return token + strconv.Itoa(page)
+	}
+
+	return base + " " + token + strconv.Itoa(page)
+}
@@ -0,0 +1,156 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func testNumberedItems(n int) Items {
+	items := make(Items, n)
+	for i := range items {
+		items[i] = NewItem(strconv.Itoa(i + 1))
+	}
+
+	return items
+}
+
+func TestParsePageQuery(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in   string
+		base string
+		page int
+	}
+
+	tests := []Test{
+		{in: "", base: "", page: 1},
+		{in: "term", base: "term", page: 1},
+		{in: "term page:3", base: "term", page: 3},
+		{in: "page:2", base: "", page: 2},
+		{in: "two words page:12", base: "two words", page: 12},
+		{in: "term page:0", base: "term page:0", page: 1},
+		{in: "term page:x", base: "term page:x", page: 1},
+		{in: "homepage:2", base: "homepage:2", page: 1},
+		{in: "term page:2147483647", base: "term", page: 2147483647},
+		{in: "term page:2147483648", base: "term", page: 2147483647},
+		{in: "term page:99999999999999999999999", base: "term", page: 2147483647},
+		{in: "term page:+2", base: "term page:+2", page: 1},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Parse", i), func(t *testing.T) {
+			t.Parallel()
+
+			base, page := NewPaginator().Parse(test.in)
+			if base != test.base || page != test.page {
+				t.Errorf("#%d: got: %q, %d want: %q, %d", i, base, page, test.base, test.page)
+			}
+		})
+	}
+}
+
+func TestPaginator_Page(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		query  string
+		titles []string
+		more   *Item
+	}
+
+	tests := []Test{
+		{
+			query:  "term",
+			titles: []string{"1", "2", "3", "Show more (4 remaining)"},
+			more: NewInvalidItem("Show more (4 remaining)").
+				Subtitle("Press ↩ to show page 2").
+				Autocomplete("term page:2").
+				Icon(IconForwardArrow),
+		},
+		{
+			query:  "term page:2",
+			titles: []string{"4", "5", "6", "Show more (1 remaining)"},
+			more: NewInvalidItem("Show more (1 remaining)").
+				Subtitle("Press ↩ to show page 3").
+				Autocomplete("term page:3").
+				Icon(IconForwardArrow),
+		},
+		{query: "term page:3", titles: []string{"7"}},
+		{query: "term page:9", titles: []string{}},
+		{query: "x page:184467440737095520", titles: []string{}},
+		{query: "x page:2147483647", titles: []string{}},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Page", i), func(t *testing.T) {
+			t.Parallel()
+
+			got := NewPaginator().PageSize(3).Page(test.query, testNumberedItems(7))
+
+			if titles := testItemTitles(t, got); !reflect.DeepEqual(titles, test.titles) {
+				t.Errorf("#%d: got: %q want: %q", i, titles, test.titles)
+			}
+
+			if test.more != nil {
+				testMarshalJSON(t, i, got[len(got)-1:], testItemsJSON(t, Items{test.more}))
+			}
+		})
+	}
+}
+
+func TestCursorPaginator(t *testing.T) {
+	t.Parallel()
+
+	c := NewCursorPaginator()
+
+	sf := NewScriptFilter()
+	if got := c.More(sf, "term", ""); got != nil {
+		t.Errorf("got: %v want: nil", got)
+	}
+
+	more := c.More(sf, "term", "abc")
+	want := NewInvalidItem("Show more").
+		Subtitle("Press ↩ to show page 2").
+		Autocomplete("term page:2").
+		Icon(IconForwardArrow)
+	testMarshalJSON(t, 0, Items{more}, testItemsJSON(t, Items{want}))
+
+	state, _ := (*sf.Variables())[DefaultCursorVariable].(string)
+	env := map[string]string{DefaultCursorVariable: state}
+
+	type Test struct {
+		query  string
+		base   string
+		cursor string
+	}
+
+	tests := []Test{
+		{query: "term", base: "term", cursor: ""},
+		{query: "term page:2", base: "term", cursor: "abc"},
+		{query: "term page:3", base: "term", cursor: ""},
+		{query: "other page:2", base: "other", cursor: ""},
+	}
+
+	for i, test := range tests {
+		base, cursor := c.cursor(test.query, lookupMap(env))
+		if base != test.base || cursor != test.cursor {
+			t.Errorf("#%d: got: %q, %q want: %q, %q", i, base, cursor, test.base, test.cursor)
+		}
+	}
+
+	sf = NewScriptFilter()
+	c.More(sf, "term page:2", "def")
+
+	if got, want := (*sf.Variables())[DefaultCursorVariable], `{"query":"term","page":3,"cursor":"def"}`; got != want {
+		t.Errorf("got: %v want: %v", got, want)
+	}
+}
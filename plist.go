@@ -0,0 +1,370 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// plistDateFormat is the layout of <date> values in XML property lists.
+const plistDateFormat = "2006-01-02T15:04:05Z"
+
+const plistHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+`
+
+// ErrInvalidPlist is returned when the input is not a valid XML property list.
+var ErrInvalidPlist = errors.New("invalid plist")
+
+// ErrUnsupportedPlistValue is returned when a value has no property list representation.
+var ErrUnsupportedPlistValue = errors.New("unsupported plist value")
+
+// DecodePlist decodes an XML property list from r.
+//
+// Values are decoded into the following Go types:
+//
+//	<dict>           map[string]interface{}
+//	<array>          []interface{}
+//	<string>         string
+//	<integer>        int64
+//	<real>           float64
+//	<true/>,<false/> bool
+//	<date>           time.Time
+//	<data>           []byte
+func DecodePlist(r io.Reader) (interface{}, error) {
+	d := &plistDecoder{dec: xml.NewDecoder(r)}
+
+	start, err := d.nextStart()
+	if err != nil {
+		return nil, err
+	}
+
+	if start.Name.Local != "plist" {
+		return d.value(start)
+	}
+
+	start, err = d.nextStart()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.value(start)
+}
+
+type plistDecoder struct {
+	dec *xml.Decoder
+}
+
+// nextStart returns the next start element, skipping everything but end elements.
+func (d *plistDecoder) nextStart() (xml.StartElement, error) {
+	for {
+		tok, err := d.dec.Token()
+		if errors.Is(err, io.EOF) {
+			return xml.StartElement{}, fmt.Errorf("%w: unexpected end of input", ErrInvalidPlist)
+		} else if err != nil {
+			return xml.StartElement{}, plistError("read token", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			return t, nil
+		case xml.EndElement:
+			return xml.StartElement{}, fmt.Errorf("%w: unexpected </%s>", ErrInvalidPlist, t.Name.Local)
+		}
+	}
+}
+
+// nextStartOrEnd returns the next start element, or false when the enclosing element ends.
+func (d *plistDecoder) nextStartOrEnd() (xml.StartElement, bool, error) {
+	for {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return xml.StartElement{}, false, plistError("read token", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			return t, true, nil
+		case xml.EndElement:
+			return xml.StartElement{}, false, nil
+		}
+	}
+}
+
+func (d *plistDecoder) text(start xml.StartElement) (string, error) {
+	var s string
+	if err := d.dec.DecodeElement(&s, &start); err != nil {
+		return "", plistError("<"+start.Name.Local+">", err)
+	}
+
+	return s, nil
+}
+
+func (d *plistDecoder) value(start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "dict":
+		return d.dict()
+	case "array":
+		return d.array()
+	case "true", "false":
+		if err := d.dec.Skip(); err != nil {
+			return nil, plistError("<"+start.Name.Local+">", err)
+		}
+
+		return start.Name.Local == "true", nil
+	}
+
+	s, err := d.text(start)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePlistScalar(start.Name.Local, s)
+}
+
+func parsePlistScalar(typ, s string) (interface{}, error) {
+	switch typ {
+	case "string":
+		return s, nil
+	case "integer":
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return nil, plistError("<integer>", err)
+		}
+
+		return n, nil
+	case "real":
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, plistError("<real>", err)
+		}
+
+		return f, nil
+	case "date":
+		t, err := time.Parse(plistDateFormat, strings.TrimSpace(s))
+		if err != nil {
+			return nil, plistError("<date>", err)
+		}
+
+		return t, nil
+	case "data":
+		b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+		if err != nil {
+			return nil, plistError("<data>", err)
+		}
+
+		return b, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown element <%s>", ErrInvalidPlist, typ)
+	}
+}
+
+func (d *plistDecoder) dict() (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+
+	for {
+		start, ok, err := d.nextStartOrEnd()
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			return m, nil
+		}
+
+		if start.Name.Local != "key" {
+			return nil, fmt.Errorf("%w: expected <key> in <dict>, got <%s>", ErrInvalidPlist, start.Name.Local)
+		}
+
+		key, err := d.text(start)
+		if err != nil {
+			return nil, err
+		}
+
+		vstart, err := d.nextStart()
+		if err != nil {
+			return nil, err
+		}
+
+		if m[key], err = d.value(vstart); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (d *plistDecoder) array() ([]interface{}, error) {
+	a := make([]interface{}, 0)
+
+	for {
+		start, ok, err := d.nextStartOrEnd()
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			return a, nil
+		}
+
+		v, err := d.value(start)
+		if err != nil {
+			return nil, err
+		}
+
+		a = append(a, v)
+	}
+}
+
+// plistError wraps ErrInvalidPlist with the context and cause of a decoding failure.
+func plistError(context string, err error) error {
+	return fmt.Errorf("%w: %s: %s", ErrInvalidPlist, context, err.Error())
+}
+
+// EncodePlist writes v to w as an XML property list, indented with tabs like Alfred does.
+//
+// It accepts the types returned by DecodePlist, as well as map[string]string,
+// []string, []map[string]interface{}, the other sized integer types and float32.
+// Dict keys are written in sorted order.
+func EncodePlist(w io.Writer, v interface{}) error {
+	bw := bufio.NewWriter(w)
+	e := &plistEncoder{w: bw}
+
+	e.writeString(plistHeader)
+	e.value(v, 0)
+	e.writeString("</plist>\n")
+
+	if e.err != nil {
+		return e.err
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write plist: %w", err)
+	}
+
+	return nil
+}
+
+// plistEscaper escapes only the characters XML requires, keeping quotes and
+// newlines readable as Alfred does.
+//
+//nolint:gochecknoglobals // stateless and shared by encoders
+var plistEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+type plistEncoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *plistEncoder) writeString(s string) {
+	if e.err != nil {
+		return
+	}
+
+	if _, err := e.w.WriteString(s); err != nil {
+		e.err = fmt.Errorf("write plist: %w", err)
+	}
+}
+
+func (e *plistEncoder) element(depth int, name, text string) {
+	e.writeString(strings.Repeat("\t", depth))
+
+	if text == "" {
+		e.writeString("<" + name + "></" + name + ">\n")
+
+		return
+	}
+
+	e.writeString("<" + name + ">" + plistEscaper.Replace(text) + "</" + name + ">\n")
+}
+
+func (e *plistEncoder) open(depth int, name string) {
+	e.writeString(strings.Repeat("\t", depth) + "<" + name + ">\n")
+}
+
+func (e *plistEncoder) close(depth int, name string) {
+	e.writeString(strings.Repeat("\t", depth) + "</" + name + ">\n")
+}
+
+//nolint:cyclop // one case per supported type
+func (e *plistEncoder) value(v interface{}, depth int) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		e.dict(v, depth)
+	case map[string]string:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = value
+		}
+
+		e.dict(m, depth)
+	case []interface{}:
+		e.array(v, depth)
+	case []string:
+		a := make([]interface{}, len(v))
+		for i, value := range v {
+			a[i] = value
+		}
+
+		e.array(a, depth)
+	case []map[string]interface{}:
+		a := make([]interface{}, len(v))
+		for i, value := range v {
+			a[i] = value
+		}
+
+		e.array(a, depth)
+	case string:
+		e.element(depth, "string", v)
+	case bool:
+		e.writeString(strings.Repeat("\t", depth) + "<" + strconv.FormatBool(v) + "/>\n")
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		e.element(depth, "integer", fmt.Sprint(v))
+	case float32:
+		e.element(depth, "real", strconv.FormatFloat(float64(v), 'g', -1, 32))
+	case float64:
+		e.element(depth, "real", strconv.FormatFloat(v, 'g', -1, 64))
+	case time.Time:
+		e.element(depth, "date", v.UTC().Format(plistDateFormat))
+	case []byte:
+		e.element(depth, "data", base64.StdEncoding.EncodeToString(v))
+	default:
+		if e.err == nil {
+			e.err = fmt.Errorf("%w: %T", ErrUnsupportedPlistValue, v)
+		}
+	}
+}
+
+func (e *plistEncoder) dict(m map[string]interface{}, depth int) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	e.open(depth, "dict")
+
+	for _, key := range keys {
+		e.element(depth+1, "key", key)
+		e.value(m[key], depth+1)
+	}
+
+	e.close(depth, "dict")
+}
+
+func (e *plistEncoder) array(a []interface{}, depth int) {
+	e.open(depth, "array")
+
+	for _, v := range a {
+		e.value(v, depth+1)
+	}
+
+	e.close(depth, "array")
+}
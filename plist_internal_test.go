@@ -0,0 +1,183 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodePlist(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in  string
+		out interface{}
+	}
+
+	tests := []Test{
+		{in: `<plist><string>a &amp; b</string></plist>`, out: "a & b"},
+		{in: `<plist><string></string></plist>`, out: ""},
+		{in: `<plist><integer> -42 </integer></plist>`, out: int64(-42)},
+		{in: `<plist><real>1.5</real></plist>`, out: 1.5},
+		{in: `<plist><true/></plist>`, out: true},
+		{in: `<plist><false/></plist>`, out: false},
+		{in: `<plist><date>2021-03-06T12:30:00Z</date></plist>`, out: time.Date(2021, time.March, 6, 12, 30, 0, 0, time.UTC)},
+		{in: "<plist><data>\n\taGVs\n\tbG8=\n</data></plist>", out: []byte("hello")},
+		{in: `<plist><array/></plist>`, out: []interface{}{}},
+		{in: `<plist><dict/></plist>`, out: map[string]interface{}{}},
+		// Without the plist root element
+		{in: `<string>bare</string>`, out: "bare"},
+		// Nested
+		{
+			in: `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<!-- comment -->
+	<key>array</key>
+	<array>
+		<integer>1</integer>
+		<dict>
+			<key>bool</key>
+			<true/>
+		</dict>
+	</array>
+	<key>string</key>
+	<string>value</string>
+</dict>
+</plist>`,
+			out: map[string]interface{}{
+				"array":  []interface{}{int64(1), map[string]interface{}{"bool": true}},
+				"string": "value",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:DecodePlist", i), func(t *testing.T) {
+			t.Parallel()
+			out, err := DecodePlist(strings.NewReader(test.in))
+			if err != nil {
+				t.Fatalf("#%d: decode error: %v", i, err)
+			}
+			if !reflect.DeepEqual(out, test.out) {
+				t.Errorf("#%d: got: %#v want: %#v", i, out, test.out)
+			}
+		})
+	}
+}
+
+func TestDecodePlist_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		``,
+		`<plist>`,
+		`<plist><integer>a</integer></plist>`,
+		`<plist><real>a</real></plist>`,
+		`<plist><date>yesterday</date></plist>`,
+		`<plist><data>!!!</data></plist>`,
+		`<plist><unknown/></plist>`,
+		`<plist><dict><string>no key</string></dict></plist>`,
+		`<plist><dict><key>a</key></dict></plist>`,
+		`<plist><array><string>unclosed</array></plist>`,
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:DecodePlist", i), func(t *testing.T) {
+			t.Parallel()
+			if _, err := DecodePlist(strings.NewReader(test)); !errors.Is(err, ErrInvalidPlist) {
+				t.Errorf("#%d: got: %v want: %v", i, err, ErrInvalidPlist)
+			}
+		})
+	}
+}
+
+func TestEncodePlist(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in  interface{}
+		out string
+	}
+
+	tests := []Test{
+		{in: "a & b", out: "<string>a &amp; b</string>\n"},
+		{in: `<a href="x">'b'</a>`, out: `<string>&lt;a href="x"&gt;'b'&lt;/a&gt;</string>` + "\n"},
+		{in: "a\n\tb", out: "<string>a\n\tb</string>\n"},
+		{in: "", out: "<string></string>\n"},
+		{in: 42, out: "<integer>42</integer>\n"},
+		{in: int64(-1), out: "<integer>-1</integer>\n"},
+		{in: 1.5, out: "<real>1.5</real>\n"},
+		{in: true, out: "<true/>\n"},
+		{in: false, out: "<false/>\n"},
+		{in: time.Date(2021, time.March, 6, 12, 30, 0, 0, time.UTC), out: "<date>2021-03-06T12:30:00Z</date>\n"},
+		{in: []byte("hello"), out: "<data>aGVsbG8=</data>\n"},
+		{in: []string{"a"}, out: "<array>\n\t<string>a</string>\n</array>\n"},
+		{
+			in:  map[string]interface{}{"b": 1, "a": map[string]string{"k": "v"}},
+			out: "<dict>\n\t<key>a</key>\n\t<dict>\n\t\t<key>k</key>\n\t\t<string>v</string>\n\t</dict>\n\t<key>b</key>\n\t<integer>1</integer>\n</dict>\n",
+		},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:EncodePlist", i), func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := EncodePlist(&buf, test.in); err != nil {
+				t.Fatalf("#%d: encode error: %v", i, err)
+			}
+			if want := plistHeader + test.out + "</plist>\n"; buf.String() != want {
+				t.Errorf("#%d: got: %s want: %s", i, buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestEncodePlist_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	in := map[string]interface{}{
+		"array":   []interface{}{"a", int64(1), 2.5, true},
+		"data":    []byte{0, 1, 2},
+		"date":    time.Date(2021, time.March, 6, 0, 0, 0, 0, time.UTC),
+		"dict":    map[string]interface{}{"nested": "value"},
+		"integer": int64(7),
+		"script":  "query=\"$1\"\n\nif [ -n \"$query\" ]; then\n\t./workflow filter \"$query\" < /dev/null && echo 'done'\nfi\n",
+	}
+
+	var buf bytes.Buffer
+	if err := EncodePlist(&buf, in); err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+
+	out, err := DecodePlist(&buf)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got: %#v want: %#v", out, in)
+	}
+}
+
+func TestEncodePlist_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	in := map[string]interface{}{"channel": make(chan int)}
+	if err := EncodePlist(io.Discard, in); !errors.Is(err, ErrUnsupportedPlistValue) {
+		t.Errorf("got: %v want: %v", err, ErrUnsupportedPlistValue)
+	}
+}
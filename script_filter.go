@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 )
 
 // Variables describes a set of variables.
@@ -20,8 +21,17 @@ func (v *Variables) Put(key string, value interface{}) {
 
 // ScriptFilter represents the output for Alfred Script Filter.
 type ScriptFilter struct {
-	items     Items
-	variables Variables
+	items         Items
+	variables     Variables
+	skipKnowledge *bool
+	cache         *scriptFilterCache
+	target        *Version
+	format        OutputFormat
+}
+
+type scriptFilterCache struct {
+	Seconds     int  `json:"seconds"`
+	LooseReload bool `json:"loosereload,omitempty"`
 }
 
 // NewScriptFilter returns a new initialized alfred.ScriptFilter.
@@ -38,26 +48,142 @@ func (sf *ScriptFilter) Items() *Items {
 }
 
 // Variables return Variables bound to this ScriptFilter.
+//
+// Alfred passes these variables back as environment variables when it reruns
+// the Script Filter as the query changes, and to the outputs of an actioned
+// item, so they carry state from one run to the next.
 func (sf *ScriptFilter) Variables() *Variables {
 	return &sf.variables
 }
 
+// SkipKnowledge sets whether Alfred ignores its knowledge of the items order,
+// available since Alfred 5.
+func (sf *ScriptFilter) SkipKnowledge(skip bool) *ScriptFilter {
+	sf.skipKnowledge = &skip
+
+	return sf
+}
+
+// Cache sets how long Alfred caches the results, available since Alfred 5.5.
+// With looseReload, stale results are shown while the script runs again.
+func (sf *ScriptFilter) Cache(ttl time.Duration, looseReload bool) *ScriptFilter {
+	sf.cache = &scriptFilterCache{Seconds: int(ttl / time.Second), LooseReload: looseReload}
+
+	return sf
+}
+
+// TargetAlfred sets the Alfred version the output is written for.
+//
+// Fields the version does not support are left out. It defaults to the version
+// of the running Alfred; outside Alfred, no field is left out.
+func (sf *ScriptFilter) TargetAlfred(v Version) *ScriptFilter {
+	sf.target = &v
+
+	return sf
+}
+
+func (sf *ScriptFilter) targetVersion() (Version, bool) {
+	if sf.target != nil {
+		return *sf.target, true
+	}
+
+	return AlfredVersion()
+}
+
+// DroppedFields describes the fields left out of the output because the target
+// Alfred version does not support them.
+func (sf *ScriptFilter) DroppedFields() []string {
+	_, dropped := sf.downgrade()
+
+	return dropped
+}
+
+// downgrade returns a copy of the ScriptFilter without the fields unsupported
+// by the target Alfred version, and a description of those fields.
+func (sf *ScriptFilter) downgrade() (*ScriptFilter, []string) {
+	target, ok := sf.targetVersion()
+	if !ok {
+		return sf, nil
+	}
+
+	out := *sf
+
+	var dropped []string
+
+	drop := func(field string, f Feature) {
+		dropped = append(dropped, fmt.Sprintf("%s requires Alfred %s (target: %s)", field, f.Since(), target))
+	}
+
+	if sf.skipKnowledge != nil && !target.Supports(FeatureSkipKnowledge) {
+		out.skipKnowledge = nil
+
+		drop("skipknowledge", FeatureSkipKnowledge)
+	}
+
+	if sf.cache != nil && !target.Supports(FeatureScriptFilterCache) {
+		out.cache = nil
+
+		drop("cache", FeatureScriptFilterCache)
+	}
+
+	if !target.Supports(FeatureItemAction) {
+		out.items = make(Items, len(sf.items))
+
+		for i, item := range sf.items {
+			out.items[i] = item
+
+			if item != nil && item.action != nil {
+				c := *item
+				c.action = nil
+				out.items[i] = &c
+
+				drop(fmt.Sprintf("action of item %q", item.title), FeatureItemAction)
+			}
+		}
+	}
+
+	return &out, dropped
+}
+
 // MarshalJSON implements the json.Marshaler interface.
 func (sf *ScriptFilter) MarshalJSON() ([]byte, error) {
+	sf, _ = sf.downgrade()
+
 	v := &struct {
-		Items     Items                  `json:"items"`
-		Variables map[string]interface{} `json:"variables,omitempty"`
+		Items         Items                  `json:"items"`
+		Variables     map[string]interface{} `json:"variables,omitempty"`
+		SkipKnowledge *bool                  `json:"skipknowledge,omitempty"`
+		Cache         *scriptFilterCache     `json:"cache,omitempty"`
 	}{
-		Items:     sf.items,
-		Variables: sf.variables,
+		Items:         sf.items,
+		Variables:     sf.variables,
+		SkipKnowledge: sf.skipKnowledge,
+		Cache:         sf.cache,
 	}
 
 	return json.Marshal(v)
 }
 
+// Format sets the format written by Output and OutputIndent.
+func (sf *ScriptFilter) Format(format OutputFormat) *ScriptFilter {
+	sf.format = format
+
+	return sf
+}
+
 // Output prints the Alfred Script Filter results to os.Stdout.
 func (sf *ScriptFilter) Output() error {
-	bytes, err := json.Marshal(sf)
+	var (
+		bytes []byte
+		err   error
+	)
+
+	if sf.format == OutputXML {
+		bytes, err = sf.marshalXML("", "")
+	} else {
+		bytes, err = json.Marshal(sf)
+	}
+
 	if err != nil {
 		return err
 	}
@@ -67,7 +193,17 @@ func (sf *ScriptFilter) Output() error {
 
 // OutputIndent is like Output but applies Indent to format the output.
 func (sf *ScriptFilter) OutputIndent(prefix, indent string) error {
-	bytes, err := json.MarshalIndent(sf, prefix, indent)
+	var (
+		bytes []byte
+		err   error
+	)
+
+	if sf.format == OutputXML {
+		bytes, err = sf.marshalXML(prefix, indent)
+	} else {
+		bytes, err = json.MarshalIndent(sf, prefix, indent)
+	}
+
 	if err != nil {
 		return err
 	}
@@ -76,6 +212,16 @@ func (sf *ScriptFilter) OutputIndent(prefix, indent string) error {
 }
 
 func (sf *ScriptFilter) output(bytes []byte) error {
+	if IsDebug() {
+		for _, d := range sf.DroppedFields() {
+			fmt.Fprintln(os.Stderr, "alfred: dropped", d)
+		}
+
+		for _, m := range sf.MissingIcons() {
+			fmt.Fprintln(os.Stderr, "alfred:", m)
+		}
+	}
+
 	if _, err := os.Stdout.Write(bytes); err != nil {
 		return fmt.Errorf("os stdout write: %w", err)
 	}
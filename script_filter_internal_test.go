@@ -7,7 +7,9 @@ package alfred
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestScriptFilter_MarshalJSON(t *testing.T) {
@@ -31,6 +33,11 @@ func TestScriptFilter_MarshalJSON(t *testing.T) {
 			in1: &ScriptFilter{items: Items{}, variables: map[string]interface{}{"bool": true, "number": 1, "string": "value"}},
 			out: []byte(`{"items":[],"variables":{"bool":true,"number":1,"string":"value"}}`),
 		},
+		// With skipknowledge and cache
+		{
+			in1: NewScriptFilter().SkipKnowledge(true).Cache(90*time.Second, true),
+			out: []byte(`{"items":[],"skipknowledge":true,"cache":{"seconds":90,"loosereload":true}}`),
+		},
 	}
 
 	for i, test := range tests {
@@ -47,3 +54,88 @@ func TestScriptFilter_MarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestScriptFilter_TargetAlfred(t *testing.T) {
+	t.Parallel()
+
+	newScriptFilter := func(target string) *ScriptFilter {
+		sf := NewScriptFilter().SkipKnowledge(true).Cache(time.Minute, false).TargetAlfred(MustParseVersion(target))
+		sf.Items().Append(
+			NewItem("a").Action(NewAction().Text("a")),
+			NewItem("b"),
+		)
+
+		return sf
+	}
+
+	type Test struct {
+		target  string
+		out     string
+		dropped []string
+	}
+
+	tests := []Test{
+		{
+			target: "5.5",
+			out: `{"items":[{"title":"a","action":{"text":["a"]}},{"title":"b"}],` +
+				`"skipknowledge":true,"cache":{"seconds":60}}`,
+		},
+		{
+			target:  "5.1",
+			out:     `{"items":[{"title":"a","action":{"text":["a"]}},{"title":"b"}],"skipknowledge":true}`,
+			dropped: []string{"cache requires Alfred 5.5.0 (target: 5.1.0)"},
+		},
+		{
+			target: "4.6",
+			out:    `{"items":[{"title":"a","action":{"text":["a"]}},{"title":"b"}]}`,
+			dropped: []string{
+				"skipknowledge requires Alfred 5.0.0 (target: 4.6.0)",
+				"cache requires Alfred 5.5.0 (target: 4.6.0)",
+			},
+		},
+		{
+			target: "4.0",
+			out:    `{"items":[{"title":"a"},{"title":"b"}]}`,
+			dropped: []string{
+				"skipknowledge requires Alfred 5.0.0 (target: 4.0.0)",
+				"cache requires Alfred 5.5.0 (target: 4.0.0)",
+				`action of item "a" requires Alfred 4.5.0 (target: 4.0.0)`,
+			},
+		},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:TargetAlfred", i), func(t *testing.T) {
+			t.Parallel()
+			sf := newScriptFilter(test.target)
+			got, err := sf.MarshalJSON()
+			if err != nil {
+				t.Fatalf("#%d: marshal error: %v", i, err)
+			}
+			if string(got) != test.out {
+				t.Errorf("#%d: got: %s want: %s", i, got, test.out)
+			}
+			if dropped := sf.DroppedFields(); !reflect.DeepEqual(dropped, test.dropped) {
+				t.Errorf("#%d: dropped: got: %q want: %q", i, dropped, test.dropped)
+			}
+			if sf.items[0].action == nil || sf.skipKnowledge == nil {
+				t.Errorf("#%d: the script filter was modified", i)
+			}
+		})
+	}
+}
+
+func TestScriptFilter_TargetAlfredEnv(t *testing.T) {
+	t.Setenv(EnvAlfredVersion, "4.0.9")
+
+	sf := NewScriptFilter().SkipKnowledge(true)
+
+	if got := sf.DroppedFields(); len(got) != 1 {
+		t.Errorf("got: %q want: skipknowledge dropped", got)
+	}
+
+	if got := sf.TargetAlfred(MustParseVersion("5")).DroppedFields(); got != nil {
+		t.Errorf("got: %q want: nothing dropped", got)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// OutputFormat is the format of the Script Filter output.
+type OutputFormat int
+
+// output formats.
+const (
+	// OutputJSON is the JSON format of Alfred 3 and later.
+	OutputJSON OutputFormat = iota
+	// OutputXML is the legacy <items> XML format understood by Alfred 2.
+	OutputXML
+)
+
+type xmlItems struct {
+	XMLName xml.Name  `xml:"items"`
+	Items   []xmlItem `xml:"item"`
+}
+
+type xmlItem struct {
+	UID          *string   `xml:"uid,attr,omitempty"`
+	Arg          *string   `xml:"arg,attr,omitempty"`
+	Valid        string    `xml:"valid,attr,omitempty"`
+	Autocomplete *string   `xml:"autocomplete,attr,omitempty"`
+	Type         *ItemType `xml:"type,attr,omitempty"`
+	Title        string    `xml:"title"`
+	Subtitle     *string   `xml:"subtitle,omitempty"`
+	Icon         *xmlIcon  `xml:"icon,omitempty"`
+	Mods         []xmlMod  `xml:"mod"`
+	Texts        []xmlText `xml:"text"`
+	QuicklookURL *string   `xml:"quicklookurl,omitempty"`
+}
+
+type xmlIcon struct {
+	Type *IconType `xml:"type,attr,omitempty"`
+	Path string    `xml:",chardata"`
+}
+
+type xmlMod struct {
+	Key      string  `xml:"key,attr"`
+	Subtitle *string `xml:"subtitle,attr,omitempty"`
+	Arg      *string `xml:"arg,attr,omitempty"`
+	Valid    string  `xml:"valid,attr,omitempty"`
+}
+
+type xmlText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// MarshalXML implements the xml.Marshaler interface with the legacy <items> format.
+//
+// Variables, match and actions have no equivalent in this format and are left out.
+func (sf *ScriptFilter) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	v := xmlItems{Items: make([]xmlItem, 0, len(sf.items))}
+	for _, item := range sf.items {
+		if item != nil {
+			v.Items = append(v.Items, item.xml())
+		}
+	}
+
+	if err := e.Encode(v); err != nil {
+		return fmt.Errorf("xml encode: %w", err)
+	}
+
+	return nil
+}
+
+func (sf *ScriptFilter) marshalXML(prefix, indent string) ([]byte, error) {
+	bytes, err := xml.MarshalIndent(sf, prefix, indent)
+	if err != nil {
+		return nil, fmt.Errorf("xml marshal: %w", err)
+	}
+
+	return append([]byte(xml.Header), bytes...), nil
+}
+
+func (i *Item) xml() xmlItem {
+	v := xmlItem{
+		UID:          i.uid,
+		Arg:          i.arg,
+		Valid:        xmlBool(i.valid),
+		Autocomplete: i.autocomplete,
+		Type:         i.typ,
+		Title:        i.title,
+		Subtitle:     i.subtitle,
+		QuicklookURL: i.quicklookURL,
+	}
+
+	if i.icon != nil {
+		icon := i.icon.available(CurrentTheme())
+		v.Icon = &xmlIcon{Type: icon.typ, Path: icon.resolve(CurrentTheme())}
+	}
+
+	if i.mods != nil {
+		for _, m := range []struct {
+			key string
+			mod *Modifier
+		}{
+			{key: "shift", mod: i.mods.shift},
+			{key: "fn", mod: i.mods.fn},
+			{key: "ctrl", mod: i.mods.ctrl},
+			{key: "alt", mod: i.mods.alt},
+			{key: "cmd", mod: i.mods.cmd},
+		} {
+			if m.mod != nil {
+				v.Mods = append(v.Mods, xmlMod{Key: m.key, Subtitle: m.mod.subtitle, Arg: m.mod.arg, Valid: xmlBool(m.mod.valid)})
+			}
+		}
+	}
+
+	if i.text != nil {
+		if i.text.copy != nil {
+			v.Texts = append(v.Texts, xmlText{Type: "copy", Value: *i.text.copy})
+		}
+
+		if i.text.largeType != nil {
+			v.Texts = append(v.Texts, xmlText{Type: "largetype", Value: *i.text.largeType})
+		}
+	}
+
+	return v
+}
+
+func xmlBool(b *bool) string {
+	switch {
+	case b == nil:
+		return ""
+	case *b:
+		return "yes"
+	default:
+		return "no"
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScriptFilter_MarshalXML(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in  *Item
+		out string
+	}
+
+	tests := []Test{
+		// Minimal
+		{in: NewItem("title"), out: `<item><title>title</title></item>`},
+		// With attributes
+		{
+			in: NewItem("title").UID("uid").Arg("arg").Valid(false).Autocomplete("auto").Type(ItemTypeFile),
+			out: `<item uid="uid" arg="arg" valid="no" autocomplete="auto" type="file">` +
+				`<title>title</title></item>`,
+		},
+		// With subtitle and icon
+		{
+			in: NewItem("title").Subtitle("sub & more").Icon(NewIconWithType("~/Desktop", IconTypeFileIcon)),
+			out: `<item><title>title</title><subtitle>sub &amp; more</subtitle>` +
+				`<icon type="fileicon">~/Desktop</icon></item>`,
+		},
+		// With mods
+		{
+			in: NewItem("title").
+				ModCmd(NewModifier().Subtitle("open").Arg("cmd").Valid(true)).
+				ModShift(NewModifier().Subtitle("shift")),
+			out: `<item><title>title</title><mod key="shift" subtitle="shift"></mod>` +
+				`<mod key="cmd" subtitle="open" arg="cmd" valid="yes"></mod></item>`,
+		},
+		// With text and quicklookurl
+		{
+			in: NewItem("title").CopyText("copy").LargeText("large").QuicklookURL("https://example.com"),
+			out: `<item><title>title</title><text type="copy">copy</text><text type="largetype">large</text>` +
+				`<quicklookurl>https://example.com</quicklookurl></item>`,
+		},
+		// Without equivalent
+		{
+			in:  NewItem("title").Match("match").Action(NewAction().Text("text")),
+			out: `<item><title>title</title></item>`,
+		},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:MarshalXML", i), func(t *testing.T) {
+			t.Parallel()
+			sf := NewScriptFilter().Format(OutputXML)
+			sf.Items().Append(test.in)
+			got, err := sf.marshalXML("", "")
+			if err != nil {
+				t.Fatalf("#%d: marshal error: %v", i, err)
+			}
+			want := `<?xml version="1.0" encoding="UTF-8"?>` + "\n<items>" + test.out + "</items>"
+			if string(got) != want {
+				t.Errorf("#%d: got: %s want: %s", i, got, want)
+			}
+		})
+	}
+}
+
+func TestScriptFilter_MarshalXMLIndent(t *testing.T) {
+	t.Parallel()
+
+	sf := NewScriptFilter()
+	sf.Items().Append(NewItem("a").Arg("1"), nil, NewItem("b"))
+
+	got, err := sf.marshalXML("", "  ")
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<items>
+  <item arg="1">
+    <title>a</title>
+  </item>
+  <item>
+    <title>b</title>
+  </item>
+</items>`
+	if string(got) != want {
+		t.Errorf("got: %s want: %s", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Theme is the brightness of the Alfred theme.
+type Theme int
+
+// themes.
+const (
+	ThemeUnknown Theme = iota
+	ThemeLight
+	ThemeDark
+)
+
+// String implements the fmt.Stringer interface.
+func (t Theme) String() string {
+	switch t {
+	case ThemeLight:
+		return "light"
+	case ThemeDark:
+		return "dark"
+	default:
+		return "unknown"
+	}
+}
+
+// CurrentTheme returns the theme of the running Alfred, from alfred_theme_background.
+func CurrentTheme() Theme {
+	return ThemeFromBackground(os.Getenv(EnvThemeBackground))
+}
+
+// ThemeFromBackground returns the theme of a background color given as
+// "rgba(r,g,b,a)" or "rgb(r,g,b)", such as alfred_theme_background.
+//
+// The theme is dark when white contrasts more with the color than black does.
+// It returns ThemeUnknown when the color cannot be parsed.
+func ThemeFromBackground(background string) Theme {
+	r, g, b, ok := parseRGB(background)
+	if !ok {
+		return ThemeUnknown
+	}
+
+	// luminance at which the contrast ratios with black and white are equal.
+	const threshold = 0.179
+
+	if luminance(r, g, b) < threshold {
+		return ThemeDark
+	}
+
+	return ThemeLight
+}
+
+func parseRGB(s string) (float64, float64, float64, bool) {
+	s = strings.ToLower(strings.ReplaceAll(s, " ", ""))
+
+	var args string
+
+	switch {
+	case strings.HasPrefix(s, "rgba(") && strings.HasSuffix(s, ")"):
+		args = s[len("rgba(") : len(s)-1]
+	case strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")"):
+		args = s[len("rgb(") : len(s)-1]
+	default:
+		return 0, 0, 0, false
+	}
+
+	const channels = 3
+
+	parts := strings.Split(args, ",")
+	if len(parts) != channels && len(parts) != channels+1 {
+		return 0, 0, 0, false
+	}
+
+	var rgb [channels]float64
+
+	for i := range rgb {
+		n, err := strconv.ParseFloat(parts[i], 64)
+		if err != nil || n < 0 || n > 255 {
+			return 0, 0, 0, false
+		}
+
+		rgb[i] = n / 255 //nolint:gomnd // 8 bit channel
+	}
+
+	return rgb[0], rgb[1], rgb[2], true
+}
+
+// luminance returns the relative luminance of an sRGB color as defined by WCAG 2.
+//
+//nolint:gomnd // WCAG coefficients
+func luminance(r, g, b float64) float64 {
+	linear := func(c float64) float64 {
+		if c <= 0.03928 {
+			return c / 12.92
+		}
+
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
+
+// resolve returns the path of the icon for theme, falling back to the default path.
+func (i *Icon) resolve(theme Theme) string {
+	switch {
+	case theme == ThemeLight && i.light != "":
+		return i.light
+	case theme == ThemeDark && i.dark != "":
+		return i.dark
+	default:
+		return i.path
+	}
+}
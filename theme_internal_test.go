@@ -0,0 +1,85 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestThemeFromBackground(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in  string
+		out Theme
+	}
+
+	tests := []Test{
+		{in: "rgba(255,255,255,0.98)", out: ThemeLight},
+		{in: "rgba(29,29,31,0.95)", out: ThemeDark},
+		{in: "rgb(0, 0, 0)", out: ThemeDark},
+		{in: "RGBA(240, 240, 240, 1.00)", out: ThemeLight},
+		{in: "rgba(128,128,128,1)", out: ThemeLight},
+		{in: "rgba(0,0,255,1)", out: ThemeDark},
+		{in: "rgba(255,255,0,1)", out: ThemeLight},
+		{in: "", out: ThemeUnknown},
+		{in: "#ffffff", out: ThemeUnknown},
+		{in: "rgba(256,0,0,1)", out: ThemeUnknown},
+		{in: "rgba(1,2)", out: ThemeUnknown},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:ThemeFromBackground", i), func(t *testing.T) {
+			t.Parallel()
+			if got := ThemeFromBackground(test.in); got != test.out {
+				t.Errorf("#%d: got: %s want: %s", i, got, test.out)
+			}
+		})
+	}
+}
+
+func TestIcon_Resolve(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in    *Icon
+		theme Theme
+		out   string
+	}
+
+	both := func() *Icon { return NewIcon("icon.png").Light("light.png").Dark("dark.png") }
+
+	tests := []Test{
+		{in: both(), theme: ThemeUnknown, out: "icon.png"},
+		{in: both(), theme: ThemeLight, out: "light.png"},
+		{in: both(), theme: ThemeDark, out: "dark.png"},
+		{in: NewIcon("icon.png").Dark("dark.png"), theme: ThemeLight, out: "icon.png"},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:resolve", i), func(t *testing.T) {
+			t.Parallel()
+			if got := test.in.resolve(test.theme); got != test.out {
+				t.Errorf("#%d: got: %s want: %s", i, got, test.out)
+			}
+		})
+	}
+}
+
+func TestIcon_MarshalJSONTheme(t *testing.T) {
+	icon := NewIconWithType("icon.png", IconTypeFileIcon).Light("light.png").Dark("dark.png")
+
+	t.Setenv(EnvThemeBackground, "rgba(29,29,31,0.95)")
+	testMarshalJSON(t, 0, icon, `{"path":"dark.png","type":"fileicon"}`)
+
+	t.Setenv(EnvThemeBackground, "rgba(255,255,255,0.98)")
+	testMarshalJSON(t, 1, icon, `{"path":"light.png","type":"fileicon"}`)
+
+	t.Setenv(EnvThemeBackground, "")
+	testMarshalJSON(t, 2, icon, `{"path":"icon.png","type":"fileicon"}`)
+}
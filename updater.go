@@ -0,0 +1,517 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultUpdateInterval is the default time between two release checks.
+const DefaultUpdateInterval = 24 * time.Hour
+
+// updateStateFile is the file in the cache directory storing the last check.
+const updateStateFile = "update.json"
+
+// update errors.
+var (
+	ErrNoUpdate         = errors.New("no update available")
+	ErrNoChecksum       = errors.New("release has no checksum asset")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrHTTPStatus       = errors.New("unexpected http status")
+)
+
+// Release represents a published version of a workflow.
+type Release struct {
+	Version     string    `json:"version"`
+	Prerelease  bool      `json:"prerelease"`
+	AssetURL    string    `json:"asset_url"`
+	ChecksumURL string    `json:"checksum_url,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// ReleaseSource lists the releases of a workflow.
+type ReleaseSource interface {
+	Releases(ctx context.Context) ([]Release, error)
+}
+
+// GitHubReleases is a ReleaseSource reading the GitHub REST API, or a compatible one.
+//
+// A release is used when it has an asset ending in .alfredworkflow; an asset with
+// the same name followed by .sha256, as written by Packager, provides its checksum.
+type GitHubReleases struct {
+	baseURL string
+	owner   string
+	repo    string
+	client  *http.Client
+}
+
+// NewGitHubReleases returns a GitHubReleases for the repository owner/repo on github.com.
+func NewGitHubReleases(owner, repo string) *GitHubReleases {
+	return &GitHubReleases{
+		baseURL: "https://api.github.com",
+		owner:   owner,
+		repo:    repo,
+		client:  http.DefaultClient,
+	}
+}
+
+// BaseURL sets the API root, e.g. of a GitHub Enterprise or Gitea server.
+func (g *GitHubReleases) BaseURL(baseURL string) *GitHubReleases {
+	g.baseURL = strings.TrimSuffix(baseURL, "/")
+
+	return g
+}
+
+// Client sets the HTTP client.
+func (g *GitHubReleases) Client(client *http.Client) *GitHubReleases {
+	g.client = client
+
+	return g
+}
+
+type githubRelease struct {
+	TagName     string    `json:"tag_name"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// Releases implements the ReleaseSource interface.
+func (g *GitHubReleases) Releases(ctx context.Context) ([]Release, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/releases", g.baseURL, url.PathEscape(g.owner), url.PathEscape(g.repo))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("list releases: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("list releases: %w: %s", ErrHTTPStatus, resp.Status)
+	}
+
+	var list []githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("decode releases: %w", err)
+	}
+
+	releases := make([]Release, 0, len(list))
+
+	for _, r := range list {
+		if r.Draft {
+			continue
+		}
+
+		rel := Release{Version: r.TagName, Prerelease: r.Prerelease, PublishedAt: r.PublishedAt}
+		urls := make(map[string]string, len(r.Assets))
+
+		for _, a := range r.Assets {
+			urls[a.Name] = a.URL
+			if strings.HasSuffix(a.Name, WorkflowArchiveExt) && rel.AssetURL == "" {
+				rel.AssetURL = a.URL
+			}
+		}
+
+		if rel.AssetURL == "" {
+			continue
+		}
+
+		rel.ChecksumURL = urls[path.Base(rel.AssetURL)+".sha256"]
+		releases = append(releases, rel)
+	}
+
+	return releases, nil
+}
+
+// updateState is the result of the last check, cached between runs.
+type updateState struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    *Release  `json:"latest,omitempty"`
+}
+
+// Updater checks a ReleaseSource for newer versions of the workflow and installs them.
+//
+// A typical Script Filter checks in the background of its normal work and
+// prepends the Item returned by Item to its results. The Item autocompletes to
+// the magic argument installing the update, so the Script Filter hands its
+// query to a MagicArgs using the same Updater first:
+//
+//	u := alfred.NewUpdater(alfred.NewGitHubReleases("owner", "repo"), alfred.WorkflowVersion())
+//	if alfred.NewMagicArgs().Updater(u).Handle(query, sf) {
+//		return sf.Output()
+//	}
+//	_ = u.CheckIfDue(ctx)
+//	if item := u.Item(); item != nil {
+//		sf.Items().Prepend(item)
+//	}
+type Updater struct {
+	source     ReleaseSource
+	current    string
+	interval   time.Duration
+	cacheDir   string
+	prerelease bool
+	install    string
+	client     *http.Client
+	open       func(path string) error
+	now        func() time.Time
+}
+
+// NewUpdater returns an Updater for the workflow at currentVersion,
+// caching its checks in CacheDir.
+func NewUpdater(source ReleaseSource, currentVersion string) *Updater {
+	return &Updater{
+		source:   source,
+		current:  currentVersion,
+		interval: DefaultUpdateInterval,
+		cacheDir: CacheDir(),
+		install:  DefaultMagicPrefix + magicInstallCommand,
+		client:   http.DefaultClient,
+		open:     openFile,
+		now:      time.Now,
+	}
+}
+
+// Interval sets the minimum time between two checks done by CheckIfDue.
+func (u *Updater) Interval(interval time.Duration) *Updater {
+	u.interval = interval
+
+	return u
+}
+
+// CacheDir sets the directory storing the last check and downloads.
+func (u *Updater) CacheDir(dir string) *Updater {
+	u.cacheDir = dir
+
+	return u
+}
+
+// Prerelease sets whether prereleases are offered as updates.
+func (u *Updater) Prerelease(prerelease bool) *Updater {
+	u.prerelease = prerelease
+
+	return u
+}
+
+// InstallQuery sets the query the Item autocompletes to, the magic argument
+// installing the update; set it when MagicArgs uses another prefix.
+func (u *Updater) InstallQuery(query string) *Updater {
+	u.install = query
+
+	return u
+}
+
+// Client sets the HTTP client used for downloads.
+func (u *Updater) Client(client *http.Client) *Updater {
+	u.client = client
+
+	return u
+}
+
+// Check queries the release source and returns the newest release if it is newer
+// than the current version, or ErrNoUpdate.
+func (u *Updater) Check(ctx context.Context) (*Release, error) {
+	releases, err := u.source.Releases(ctx)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // errors of the source are returned as is
+	}
+
+	var latest *Release
+
+	for i := range releases {
+		r := releases[i]
+		if r.Prerelease && !u.prerelease {
+			continue
+		}
+
+		if latest == nil || isNewerVersion(r.Version, latest.Version) {
+			latest = &r
+		}
+	}
+
+	state := updateState{CheckedAt: u.now()}
+	if latest != nil && isNewerVersion(latest.Version, u.current) {
+		state.Latest = latest
+	}
+
+	if err := u.saveState(state); err != nil {
+		return nil, err
+	}
+
+	if state.Latest == nil {
+		return nil, ErrNoUpdate
+	}
+
+	return state.Latest, nil
+}
+
+// CheckIfDue calls Check unless the last check is more recent than the interval.
+func (u *Updater) CheckIfDue(ctx context.Context) error {
+	if state, ok := u.loadState(); ok && u.now().Sub(state.CheckedAt) < u.interval {
+		return nil
+	}
+
+	if _, err := u.Check(ctx); err != nil && !errors.Is(err, ErrNoUpdate) {
+		return err
+	}
+
+	return nil
+}
+
+// Available returns the newer release found by the last check.
+func (u *Updater) Available() (*Release, bool) {
+	state, ok := u.loadState()
+	if !ok || state.Latest == nil || !isNewerVersion(state.Latest.Version, u.current) {
+		return nil, false
+	}
+
+	return state.Latest, true
+}
+
+// Item returns an "Update available" Item to prepend to the Script Filter results,
+// or nil when no newer release was found by the last check. Actioning it
+// autocompletes to the install query, so the update is only installed by
+// Install, once its checksum is verified.
+func (u *Updater) Item() *Item {
+	rel, ok := u.Available()
+	if !ok {
+		return nil
+	}
+
+	return u.releaseItem(rel)
+}
+
+func (u *Updater) releaseItem(rel *Release) *Item {
+	return NewInvalidItem("Update available: " + rel.Version).
+		Subtitle("Action to install the update (installed: " + u.current + ")").
+		Autocomplete(u.install).
+		Icon(IconSync)
+}
+
+// Download downloads the asset of rel to the cache directory, verifies its
+// checksum and returns the path of the downloaded file.
+func (u *Updater) Download(ctx context.Context, rel *Release) (string, error) {
+	if rel.ChecksumURL == "" {
+		return "", ErrNoChecksum
+	}
+
+	want, err := u.fetchChecksum(ctx, rel.ChecksumURL)
+	if err != nil {
+		return "", err
+	}
+
+	dir := u.cacheDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	if _, err := EnsureDir(dir); err != nil {
+		return "", fmt.Errorf("create cache dir: %w", err)
+	}
+
+	body, err := u.get(ctx, rel.AssetURL)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	dst := filepath.Join(dir, path.Base(rel.AssetURL))
+
+	got, err := writeHashed(dst, body)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.EqualFold(got, want) {
+		_ = os.Remove(dst)
+
+		return "", fmt.Errorf("%w: got %s want %s", ErrChecksumMismatch, got, want)
+	}
+
+	return dst, nil
+}
+
+// Install downloads the newer release found by the last check, or by a new
+// check when none is cached, verifies its checksum and opens it, which makes
+// Alfred import the workflow.
+func (u *Updater) Install(ctx context.Context) error {
+	rel, ok := u.Available()
+	if !ok {
+		var err error
+		if rel, err = u.Check(ctx); err != nil {
+			return err
+		}
+	}
+
+	file, err := u.Download(ctx, rel)
+	if err != nil {
+		return err
+	}
+
+	return u.open(file)
+}
+
+func (u *Updater) fetchChecksum(ctx context.Context, checksumURL string) (string, error) {
+	body, err := u.get(ctx, checksumURL)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	const maxChecksumSize = 1024
+
+	data, err := io.ReadAll(io.LimitReader(body, maxChecksumSize))
+	if err != nil {
+		return "", fmt.Errorf("read checksum: %w", err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", ErrNoChecksum
+	}
+
+	return fields[0], nil
+}
+
+func (u *Updater) get(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", rawURL, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+
+		return nil, fmt.Errorf("download %s: %w: %s", rawURL, ErrHTTPStatus, resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+func (u *Updater) statePath() string {
+	if u.cacheDir == "" {
+		return ""
+	}
+
+	return filepath.Join(u.cacheDir, updateStateFile)
+}
+
+func (u *Updater) loadState() (updateState, bool) {
+	var state updateState
+
+	p := u.statePath()
+	if p == "" {
+		return state, false
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return state, false
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, false
+	}
+
+	return state, true
+}
+
+func (u *Updater) saveState(state updateState) error {
+	p := u.statePath()
+	if p == "" {
+		return nil
+	}
+
+	if _, err := EnsureDir(u.cacheDir); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	const perm = 0o600
+
+	if err := os.WriteFile(p, data, perm); err != nil {
+		return fmt.Errorf("write update state: %w", err)
+	}
+
+	return nil
+}
+
+// writeHashed copies r to the file dst and returns the hex encoded SHA-256 of the contents.
+func writeHashed(dst string, r io.Reader) (string, error) {
+	f, err := os.Create(dst)
+	if err != nil {
+		return "", fmt.Errorf("create %s: %w", dst, err)
+	}
+
+	hash := sha256.New()
+
+	if _, err := io.Copy(io.MultiWriter(f, hash), r); err != nil {
+		_ = f.Close()
+		_ = os.Remove(dst)
+
+		return "", fmt.Errorf("write %s: %w", dst, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("close %s: %w", dst, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// openFile opens path with its default application.
+func openFile(path string) error {
+	if err := exec.Command("open", path).Run(); err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// isNewerVersion reports whether v is a valid version newer than current;
+// any valid version is newer than an invalid current version.
+func isNewerVersion(v, current string) bool {
+	nv, err := ParseVersion(v)
+	if err != nil {
+		return false
+	}
+
+	cv, err := ParseVersion(current)
+
+	return err != nil || nv.Compare(cv) > 0
+}
@@ -0,0 +1,293 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testAsset = "workflow archive"
+
+type testReleaseServer struct {
+	*httptest.Server
+	listCalls int32
+	checksum  string
+}
+
+func newTestReleaseServer(t *testing.T) *testReleaseServer {
+	t.Helper()
+
+	sum := sha256.Sum256([]byte(testAsset))
+	s := &testReleaseServer{checksum: hex.EncodeToString(sum[:])}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/releases", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.listCalls, 1)
+		fmt.Fprintf(w, `[
+			{"tag_name": "v3.0.0", "draft": true, "assets": [{"name": "Test.alfredworkflow", "browser_download_url": "%[1]s/draft"}]},
+			{"tag_name": "v2.0.0-beta.1", "prerelease": true, "assets": [
+				{"name": "Test-2.0.0-beta.1.alfredworkflow", "browser_download_url": "%[1]s/beta/Test.alfredworkflow"}
+			]},
+			{"tag_name": "v1.2.0", "published_at": "2021-03-06T00:00:00Z", "assets": [
+				{"name": "Test-1.2.0.alfredworkflow", "browser_download_url": "%[1]s/download/Test-1.2.0.alfredworkflow"},
+				{"name": "Test-1.2.0.alfredworkflow.sha256", "browser_download_url": "%[1]s/download/Test-1.2.0.alfredworkflow.sha256"}
+			]},
+			{"tag_name": "v1.1.0", "assets": [
+				{"name": "Test-1.1.0.alfredworkflow", "browser_download_url": "%[1]s/download/Test-1.1.0.alfredworkflow"},
+				{"name": "Test-1.1.0.alfredworkflow.sha256", "browser_download_url": "%[1]s/bad.sha256"}
+			]},
+			{"tag_name": "v0.9.0", "assets": []}
+		]`, s.URL)
+	})
+	mux.HandleFunc("/download/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testAsset)
+	})
+	mux.HandleFunc("/download/Test-1.2.0.alfredworkflow.sha256", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  Test-1.2.0.alfredworkflow\n", s.checksum)
+	})
+	mux.HandleFunc("/bad.sha256", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "0000  Test-1.1.0.alfredworkflow\n")
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *testReleaseServer) source() *GitHubReleases {
+	return NewGitHubReleases("owner", "repo").BaseURL(s.URL + "/").Client(s.Client())
+}
+
+func TestIsNewerVersion(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		v       string
+		current string
+		out     bool
+	}
+
+	tests := []Test{
+		{v: "v1.2.0", current: "1.2.0", out: false},
+		{v: "v1.2.1", current: "1.2.0", out: true},
+		{v: "1.10.0", current: "1.9.0", out: true},
+		{v: "1.0.0-beta", current: "1.0.0", out: false},
+		{v: "1.0.0", current: "", out: true},
+		{v: "latest", current: "1.0.0", out: false},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:isNewerVersion", i), func(t *testing.T) {
+			t.Parallel()
+			if got := isNewerVersion(test.v, test.current); got != test.out {
+				t.Errorf("#%d: got: %t want: %t", i, got, test.out)
+			}
+		})
+	}
+}
+
+func TestGitHubReleases_Releases(t *testing.T) {
+	t.Parallel()
+
+	s := newTestReleaseServer(t)
+
+	releases, err := s.source().Releases(context.Background())
+	if err != nil {
+		t.Fatalf("releases error: %v", err)
+	}
+
+	if len(releases) != 3 {
+		t.Fatalf("got: %d releases want: 3", len(releases))
+	}
+
+	want := Release{
+		Version:     "v1.2.0",
+		AssetURL:    s.URL + "/download/Test-1.2.0.alfredworkflow",
+		ChecksumURL: s.URL + "/download/Test-1.2.0.alfredworkflow.sha256",
+		PublishedAt: time.Date(2021, time.March, 6, 0, 0, 0, 0, time.UTC),
+	}
+	if releases[1] != want {
+		t.Errorf("got: %+v want: %+v", releases[1], want)
+	}
+
+	if !releases[0].Prerelease || releases[0].ChecksumURL != "" {
+		t.Errorf("prerelease: got: %+v", releases[0])
+	}
+
+	_, err = NewGitHubReleases("owner", "missing").BaseURL(s.URL).Client(s.Client()).Releases(context.Background())
+	if !errors.Is(err, ErrHTTPStatus) {
+		t.Errorf("got: %v want: %v", err, ErrHTTPStatus)
+	}
+}
+
+func TestUpdater_Check(t *testing.T) {
+	t.Parallel()
+
+	s := newTestReleaseServer(t)
+
+	type Test struct {
+		current    string
+		prerelease bool
+		out        string
+		err        error
+	}
+
+	tests := []Test{
+		{current: "1.0.0", out: "v1.2.0"},
+		{current: "1.0.0", prerelease: true, out: "v2.0.0-beta.1"},
+		{current: "1.2.0", err: ErrNoUpdate},
+		{current: "2.0.0", prerelease: true, err: ErrNoUpdate},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Check", i), func(t *testing.T) {
+			t.Parallel()
+			u := NewUpdater(s.source(), test.current).CacheDir(t.TempDir()).Prerelease(test.prerelease)
+			rel, err := u.Check(context.Background())
+			if !errors.Is(err, test.err) {
+				t.Fatalf("#%d: got: %v want: %v", i, err, test.err)
+			}
+			if err == nil && rel.Version != test.out {
+				t.Errorf("#%d: got: %s want: %s", i, rel.Version, test.out)
+			}
+			if _, ok := u.Available(); ok != (test.err == nil) {
+				t.Errorf("#%d: available: got: %t want: %t", i, ok, test.err == nil)
+			}
+		})
+	}
+}
+
+func TestUpdater_CheckIfDue(t *testing.T) {
+	t.Parallel()
+
+	s := newTestReleaseServer(t)
+	now := time.Date(2021, time.March, 6, 0, 0, 0, 0, time.UTC)
+	u := NewUpdater(s.source(), "1.0.0").CacheDir(filepath.Join(t.TempDir(), "cache")).Interval(time.Hour)
+	u.now = func() time.Time { return now }
+
+	if u.Item() != nil {
+		t.Errorf("got an item before checking")
+	}
+
+	for _, elapsed := range []time.Duration{0, 30 * time.Minute, 2 * time.Hour} {
+		now = now.Add(elapsed)
+		if err := u.CheckIfDue(context.Background()); err != nil {
+			t.Fatalf("check error: %v", err)
+		}
+	}
+
+	if calls := atomic.LoadInt32(&s.listCalls); calls != 2 {
+		t.Errorf("got: %d checks want: 2", calls)
+	}
+
+	testMarshalJSON(t, 0, u.Item(), `{"title":"Update available: v1.2.0",`+
+		`"subtitle":"Action to install the update (installed: 1.0.0)",`+
+		`"icon":{"path":"/System/Library/CoreServices/CoreTypes.bundle/Contents/Resources/Sync.icns"},`+
+		`"valid":false,"autocomplete":"workflow:update:install"}`)
+
+	if got := u.InstallQuery("wf:update:install").Item().autocomplete; got == nil || *got != "wf:update:install" {
+		t.Errorf("got: %v want: %s", got, "wf:update:install")
+	}
+
+	// Once installed, the cached release is no longer an update.
+	if NewUpdater(s.source(), "1.2.0").CacheDir(u.cacheDir).Item() != nil {
+		t.Errorf("got an item for the installed version")
+	}
+}
+
+func TestUpdater_Download(t *testing.T) {
+	t.Parallel()
+
+	s := newTestReleaseServer(t)
+	dir := t.TempDir()
+	u := NewUpdater(s.source(), "1.0.0").CacheDir(dir).Client(s.Client())
+
+	rel := &Release{
+		Version:     "v1.2.0",
+		AssetURL:    s.URL + "/download/Test-1.2.0.alfredworkflow",
+		ChecksumURL: s.URL + "/download/Test-1.2.0.alfredworkflow.sha256",
+	}
+
+	file, err := u.Download(context.Background(), rel)
+	if err != nil {
+		t.Fatalf("download error: %v", err)
+	}
+
+	if data, err := os.ReadFile(file); err != nil || string(data) != testAsset {
+		t.Errorf("got: %q, %v want: %q", data, err, testAsset)
+	}
+
+	bad := &Release{AssetURL: s.URL + "/download/Test-1.1.0.alfredworkflow", ChecksumURL: s.URL + "/bad.sha256"}
+	if _, err := u.Download(context.Background(), bad); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("got: %v want: %v", err, ErrChecksumMismatch)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "Test-1.1.0.alfredworkflow")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("unverified download was kept: %v", err)
+	}
+
+	if _, err := u.Download(context.Background(), &Release{AssetURL: rel.AssetURL}); !errors.Is(err, ErrNoChecksum) {
+		t.Errorf("got: %v want: %v", err, ErrNoChecksum)
+	}
+
+	missing := &Release{AssetURL: s.URL + "/missing.alfredworkflow", ChecksumURL: s.URL + "/missing.sha256"}
+	if _, err := u.Download(context.Background(), missing); !errors.Is(err, ErrHTTPStatus) {
+		t.Errorf("got: %v want: %v", err, ErrHTTPStatus)
+	}
+}
+
+func TestUpdater_Install(t *testing.T) {
+	t.Parallel()
+
+	s := newTestReleaseServer(t)
+	u := NewUpdater(s.source(), "1.0.0").CacheDir(t.TempDir()).Client(s.Client())
+
+	var opened string
+
+	u.open = func(path string) error {
+		opened = path
+
+		return nil
+	}
+
+	// Without a cached check, Install checks first.
+	if err := u.Install(context.Background()); err != nil {
+		t.Fatalf("install error: %v", err)
+	}
+
+	if filepath.Base(opened) != "Test-1.2.0.alfredworkflow" {
+		t.Errorf("got: %s want: Test-1.2.0.alfredworkflow", opened)
+	}
+
+	opened = ""
+	u = NewUpdater(s.source(), "1.2.0").CacheDir("").Client(s.Client())
+	u.open = func(path string) error {
+		opened = path
+
+		return nil
+	}
+
+	if err := u.Install(context.Background()); !errors.Is(err, ErrNoUpdate) {
+		t.Errorf("got: %v want: %v", err, ErrNoUpdate)
+	}
+
+	if opened != "" {
+		t.Errorf("got: %s want: nothing opened", opened)
+	}
+}
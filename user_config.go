@@ -0,0 +1,470 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigTag is the struct tag naming the variable a field is bound to by UserConfig.Bind.
+const ConfigTag = "alfred"
+
+// ConfigFieldType is the type of a Configure Workflow field.
+type ConfigFieldType string
+
+// config field types.
+const (
+	ConfigTextField   ConfigFieldType = "textfield"
+	ConfigTextArea    ConfigFieldType = "textarea"
+	ConfigCheckbox    ConfigFieldType = "checkbox"
+	ConfigPopupButton ConfigFieldType = "popupbutton"
+	ConfigFilePicker  ConfigFieldType = "filepicker"
+)
+
+// FilePickerMode is what a file picker field accepts.
+type FilePickerMode int
+
+// file picker modes.
+const (
+	FilePickerFiles           FilePickerMode = 0
+	FilePickerFolders         FilePickerMode = 1
+	FilePickerFilesAndFolders FilePickerMode = 2
+)
+
+// configuration errors.
+var (
+	ErrConfigRequired      = errors.New("value is required")
+	ErrConfigInvalidOption = errors.New("value is not one of the options")
+	ErrConfigTarget        = errors.New("bind target must be a non-nil pointer to a struct")
+	ErrConfigUnsupported   = errors.New("unsupported field type")
+)
+
+// ConfigField represents a field of the Configure Workflow sheet.
+type ConfigField struct {
+	typ         ConfigFieldType
+	variable    string
+	label       string
+	description string
+	config      map[string]interface{}
+	options     [][2]string
+	validators  []func(string) error
+}
+
+func newConfigField(typ ConfigFieldType, variable, label string, config map[string]interface{}) *ConfigField {
+	return &ConfigField{
+		typ:      typ,
+		variable: variable,
+		label:    label,
+		config:   config,
+	}
+}
+
+// NewTextField returns a single line text field stored in variable.
+func NewTextField(variable, label string) *ConfigField {
+	return newConfigField(ConfigTextField, variable, label, map[string]interface{}{
+		"default":     "",
+		"placeholder": "",
+		"required":    false,
+		"trim":        true,
+	})
+}
+
+// NewTextArea returns a multi line text field stored in variable.
+func NewTextArea(variable, label string) *ConfigField {
+	return newConfigField(ConfigTextArea, variable, label, map[string]interface{}{
+		"default":      "",
+		"required":     false,
+		"trim":         true,
+		"verticalsize": 3, //nolint:gomnd // Alfred default
+	})
+}
+
+// NewCheckbox returns a checkbox with the given text stored in variable as "1" or "0".
+func NewCheckbox(variable, label, text string) *ConfigField {
+	return newConfigField(ConfigCheckbox, variable, label, map[string]interface{}{
+		"default":  false,
+		"required": false,
+		"text":     text,
+	})
+}
+
+// NewPopupButton returns a popup button stored in variable; add its choices with Option.
+func NewPopupButton(variable, label string) *ConfigField {
+	return newConfigField(ConfigPopupButton, variable, label, map[string]interface{}{
+		"default": "",
+	})
+}
+
+// NewFilePicker returns a file picker stored in variable.
+func NewFilePicker(variable, label string, mode FilePickerMode) *ConfigField {
+	return newConfigField(ConfigFilePicker, variable, label, map[string]interface{}{
+		"default":     "",
+		"filtermode":  int(mode),
+		"placeholder": "",
+		"required":    false,
+	})
+}
+
+// Description sets the description shown below the field.
+func (f *ConfigField) Description(description string) *ConfigField {
+	f.description = description
+
+	return f
+}
+
+// Default sets the default value; a bool for checkboxes and a string otherwise.
+func (f *ConfigField) Default(value interface{}) *ConfigField {
+	f.config["default"] = value
+
+	return f
+}
+
+// Placeholder sets the placeholder of text fields and file pickers.
+func (f *ConfigField) Placeholder(placeholder string) *ConfigField {
+	f.config["placeholder"] = placeholder
+
+	return f
+}
+
+// Required sets whether a value must be entered.
+func (f *ConfigField) Required(required bool) *ConfigField {
+	f.config["required"] = required
+
+	return f
+}
+
+// Option appends a choice of a popup button.
+func (f *ConfigField) Option(label, value string) *ConfigField {
+	f.options = append(f.options, [2]string{label, value})
+
+	return f
+}
+
+// Validate appends a validation of the raw value.
+func (f *ConfigField) Validate(validate func(value string) error) *ConfigField {
+	f.validators = append(f.validators, validate)
+
+	return f
+}
+
+func (f *ConfigField) plist() map[string]interface{} {
+	config := make(map[string]interface{}, len(f.config)+1)
+	for key, value := range f.config {
+		config[key] = value
+	}
+
+	if f.typ == ConfigPopupButton {
+		pairs := make([]interface{}, 0, len(f.options))
+		for _, o := range f.options {
+			pairs = append(pairs, []interface{}{o[0], o[1]})
+		}
+
+		config["pairs"] = pairs
+	}
+
+	return map[string]interface{}{
+		"config":      config,
+		"description": f.description,
+		"label":       f.label,
+		"type":        string(f.typ),
+		"variable":    f.variable,
+	}
+}
+
+// defaultValue returns the default in the form Alfred passes it as an environment variable.
+func (f *ConfigField) defaultValue() string {
+	switch v := f.config["default"].(type) {
+	case bool:
+		if v {
+			return "1"
+		}
+
+		return "0"
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (f *ConfigField) validate(value string) error {
+	if required, _ := f.config["required"].(bool); required && strings.TrimSpace(value) == "" {
+		return ErrConfigRequired
+	}
+
+	if f.typ == ConfigPopupButton && len(f.options) > 0 && value != "" {
+		found := false
+
+		for _, o := range f.options {
+			found = found || o[1] == value
+		}
+
+		if !found {
+			return fmt.Errorf("%w: %q", ErrConfigInvalidOption, value)
+		}
+	}
+
+	for _, validate := range f.validators {
+		if err := validate(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ConfigError reports a misconfigured variable.
+type ConfigError struct {
+	Variable string
+	Label    string
+	Err      error
+}
+
+// Error implements the error interface.
+func (e *ConfigError) Error() string {
+	return e.Variable + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// Item returns an invalid Item describing the error for Alfred results.
+func (e *ConfigError) Item() *Item {
+	name := e.Label
+	if name == "" {
+		name = e.Variable
+	}
+
+	return NewInvalidItem("Invalid configuration: " + name).
+		Subtitle(e.Err.Error()).
+		Icon(IconAlertCautionBadge)
+}
+
+// ConfigErrors is a list of ConfigError returned by UserConfig.Bind.
+type ConfigErrors []*ConfigError
+
+// Error implements the error interface.
+func (e ConfigErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+
+	return "invalid configuration: " + strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the errors matches target.
+func (e ConfigErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Items returns one invalid Item per error, to be shown instead of the Script Filter results.
+func (e ConfigErrors) Items() Items {
+	items := make(Items, 0, len(e))
+	for _, err := range e {
+		items = append(items, err.Item())
+	}
+
+	return items
+}
+
+// UserConfig represents the Configure Workflow sheet of Alfred 5.
+type UserConfig struct {
+	fields []*ConfigField
+}
+
+// NewUserConfig returns a UserConfig with the given fields in display order.
+func NewUserConfig(fields ...*ConfigField) *UserConfig {
+	return &UserConfig{fields: fields}
+}
+
+// Add appends fields.
+func (c *UserConfig) Add(fields ...*ConfigField) *UserConfig {
+	c.fields = append(c.fields, fields...)
+
+	return c
+}
+
+// Plist returns the userconfigurationconfig section of info.plist.
+func (c *UserConfig) Plist() []interface{} {
+	p := make([]interface{}, 0, len(c.fields))
+	for _, f := range c.fields {
+		p = append(p, f.plist())
+	}
+
+	return p
+}
+
+// Bind sets the fields of the struct pointed to by dst from the environment.
+//
+// Each struct field tagged `alfred:"name"` is set from the variable name, or
+// from the default of the config field for name when the variable is unset.
+// Values are validated by their config field and converted to string, bool,
+// integer, float, time.Duration or []string (one element per line) fields.
+// All problems are reported together as ConfigErrors.
+func (c *UserConfig) Bind(dst interface{}) error {
+	return c.bind(dst, os.LookupEnv)
+}
+
+func (c *UserConfig) field(variable string) *ConfigField {
+	for _, f := range c.fields {
+		if f.variable == variable {
+			return f
+		}
+	}
+
+	return nil
+}
+
+func (c *UserConfig) bind(dst interface{}, lookup func(string) (string, bool)) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrConfigTarget
+	}
+
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	var errs ConfigErrors
+
+	for i := 0; i < rt.NumField(); i++ {
+		name := rt.Field(i).Tag.Get(ConfigTag)
+		if name == "" || name == "-" {
+			continue
+		}
+
+		if !rv.Field(i).CanSet() {
+			errs = append(errs, &ConfigError{
+				Variable: name,
+				Err:      fmt.Errorf("%w: unexported field %s", ErrConfigUnsupported, rt.Field(i).Name),
+			})
+
+			continue
+		}
+
+		f := c.field(name)
+
+		value, ok := lookup(name)
+		if !ok && f != nil {
+			value = f.defaultValue()
+		}
+
+		cerr := &ConfigError{Variable: name}
+
+		if f != nil {
+			cerr.Label = f.label
+
+			if f.config["trim"] == true {
+				value = strings.TrimSpace(value)
+			}
+
+			if err := f.validate(value); err != nil {
+				cerr.Err = err
+				errs = append(errs, cerr)
+
+				continue
+			}
+		}
+
+		if err := setConfigValue(rv.Field(i), value); err != nil {
+			cerr.Err = err
+			errs = append(errs, cerr)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+//nolint:cyclop // one case per supported kind
+func setConfigValue(v reflect.Value, value string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		if value == "" {
+			v.SetInt(0)
+
+			return nil
+		}
+
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value) //nolint:goerr113 // message for the user
+		}
+
+		v.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch v.Kind() { //nolint:exhaustive // unsupported kinds are reported
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		v.SetBool(value == "1" || strings.EqualFold(value, "true") || strings.EqualFold(value, "yes"))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(orZero(value)), 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value) //nolint:goerr113 // message for the user
+		}
+
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.TrimSpace(orZero(value)), 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value) //nolint:goerr113 // message for the user
+		}
+
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(strings.TrimSpace(orZero(value)), v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value) //nolint:goerr113 // message for the user
+		}
+
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("%w: %s", ErrConfigUnsupported, v.Type())
+		}
+
+		lines := make([]string, 0)
+
+		for _, line := range strings.Split(value, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+
+		v.Set(reflect.ValueOf(lines).Convert(v.Type()))
+	default:
+		return fmt.Errorf("%w: %s", ErrConfigUnsupported, v.Type())
+	}
+
+	return nil
+}
+
+func orZero(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "0"
+	}
+
+	return value
+}
@@ -0,0 +1,289 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testConfig() *UserConfig {
+	return NewUserConfig(
+		NewTextField("api_key", "API Key").Required(true).Placeholder("token").Description("Your API key"),
+		NewTextArea("hosts", "Hosts").Default("a\nb"),
+		NewCheckbox("debug", "Debug", "Enable debug output").Default(true),
+		NewPopupButton("sort", "Sort").Option("Name", "name").Option("Date", "date").Default("name"),
+		NewFilePicker("dir", "Directory", FilePickerFolders),
+		NewTextField("limit", "Limit").Default("10").Validate(func(v string) error {
+			if len(v) > 3 {
+				return errors.New("too long") //nolint:goerr113 // test
+			}
+
+			return nil
+		}),
+	)
+}
+
+func lookupMap(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+
+		return v, ok
+	}
+}
+
+func TestUserConfig_Plist(t *testing.T) {
+	t.Parallel()
+
+	p := testConfig().Plist()
+	if len(p) != 6 {
+		t.Fatalf("got: %d fields want: 6", len(p))
+	}
+
+	type Test struct {
+		index int
+		out   map[string]interface{}
+	}
+
+	tests := []Test{
+		{
+			index: 0,
+			out: map[string]interface{}{
+				"config":      map[string]interface{}{"default": "", "placeholder": "token", "required": true, "trim": true},
+				"description": "Your API key",
+				"label":       "API Key",
+				"type":        "textfield",
+				"variable":    "api_key",
+			},
+		},
+		{
+			index: 2,
+			out: map[string]interface{}{
+				"config":      map[string]interface{}{"default": true, "required": false, "text": "Enable debug output"},
+				"description": "",
+				"label":       "Debug",
+				"type":        "checkbox",
+				"variable":    "debug",
+			},
+		},
+		{
+			index: 3,
+			out: map[string]interface{}{
+				"config": map[string]interface{}{
+					"default": "name",
+					"pairs":   []interface{}{[]interface{}{"Name", "name"}, []interface{}{"Date", "date"}},
+				},
+				"description": "",
+				"label":       "Sort",
+				"type":        "popupbutton",
+				"variable":    "sort",
+			},
+		},
+		{
+			index: 4,
+			out: map[string]interface{}{
+				"config":      map[string]interface{}{"default": "", "filtermode": 1, "placeholder": "", "required": false},
+				"description": "",
+				"label":       "Directory",
+				"type":        "filepicker",
+				"variable":    "dir",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Plist", i), func(t *testing.T) {
+			t.Parallel()
+			if !reflect.DeepEqual(p[test.index], test.out) {
+				t.Errorf("#%d: got: %v want: %v", i, p[test.index], test.out)
+			}
+		})
+	}
+}
+
+func TestUserConfig_Workflow(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := NewWorkflow("com.example.test", "Test").UserConfiguration(testConfig()).Encode(&buf); err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+
+	info, err := DecodeWorkflowInfo(&buf)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"api_key": "", "hosts": "a\nb", "debug": true, "sort": "name", "dir": "", "limit": "10",
+	}
+	if got := info.ConfigDefaults(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v want: %v", got, want)
+	}
+}
+
+type testSettings struct {
+	APIKey  string        `alfred:"api_key"`
+	Hosts   []string      `alfred:"hosts"`
+	Debug   bool          `alfred:"debug"`
+	Sort    string        `alfred:"sort"`
+	Dir     string        `alfred:"dir"`
+	Limit   int           `alfred:"limit"`
+	Ratio   float64       `alfred:"ratio"`
+	Timeout time.Duration `alfred:"timeout"`
+	Ignored string
+}
+
+func TestUserConfig_Bind(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{
+		"api_key": "  secret  ",
+		"dir":     "/tmp",
+		"ratio":   "0.5",
+		"timeout": "1m",
+		"Ignored": "value",
+	}
+
+	var got testSettings
+	if err := testConfig().bind(&got, lookupMap(env)); err != nil {
+		t.Fatalf("bind error: %v", err)
+	}
+
+	want := testSettings{
+		APIKey:  "secret",
+		Hosts:   []string{"a", "b"},
+		Debug:   true,
+		Sort:    "name",
+		Dir:     "/tmp",
+		Limit:   10,
+		Ratio:   0.5,
+		Timeout: time.Minute,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %+v want: %+v", got, want)
+	}
+}
+
+func TestUserConfig_BindErrors(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{
+		"api_key": " ",
+		"debug":   "0",
+		"sort":    "size",
+		"limit":   "1000",
+		"ratio":   "half",
+	}
+
+	var got testSettings
+
+	err := testConfig().bind(&got, lookupMap(env))
+
+	var errs ConfigErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got: %v want: ConfigErrors", err)
+	}
+
+	vars := make([]string, 0, len(errs))
+	for _, e := range errs {
+		vars = append(vars, e.Variable)
+	}
+
+	if want := []string{"api_key", "sort", "limit", "ratio"}; !reflect.DeepEqual(vars, want) {
+		t.Errorf("variables: got: %v want: %v", vars, want)
+	}
+
+	if !errors.Is(errs[0], ErrConfigRequired) || !errors.Is(errs[1], ErrConfigInvalidOption) {
+		t.Errorf("errors: got: %v", errs)
+	}
+
+	if !strings.HasPrefix(err.Error(), "invalid configuration: api_key: value is required") {
+		t.Errorf("message: got: %s", err.Error())
+	}
+
+	items := errs.Items()
+	if items.Length() != 4 {
+		t.Fatalf("items: got: %d want: 4", items.Length())
+	}
+
+	testMarshalJSON(t, 0, items[0], `{"title":"Invalid configuration: API Key","subtitle":"value is required",`+
+		`"icon":{"path":"/System/Library/CoreServices/CoreTypes.bundle/Contents/Resources/AlertCautionBadgeIcon.icns"},"valid":false}`)
+	testMarshalJSON(t, 1, items[3], `{"title":"Invalid configuration: ratio","subtitle":"invalid number \"half\"",`+
+		`"icon":{"path":"/System/Library/CoreServices/CoreTypes.bundle/Contents/Resources/AlertCautionBadgeIcon.icns"},"valid":false}`)
+}
+
+func TestUserConfig_BindTarget(t *testing.T) {
+	t.Parallel()
+
+	var s testSettings
+
+	type unsupported struct {
+		Map map[string]string `alfred:"map"`
+	}
+
+	type unexported struct {
+		name string `alfred:"name"`
+	}
+
+	tests := []struct {
+		in  interface{}
+		err error
+	}{
+		{in: s, err: ErrConfigTarget},
+		{in: (*testSettings)(nil), err: ErrConfigTarget},
+		{in: new(string), err: ErrConfigTarget},
+		{in: &unsupported{}, err: ErrConfigUnsupported},
+		{in: &unexported{}, err: ErrConfigUnsupported},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Bind", i), func(t *testing.T) {
+			t.Parallel()
+			if err := NewUserConfig().bind(test.in, lookupMap(nil)); !errors.Is(err, test.err) {
+				t.Errorf("#%d: got: %v want: %v", i, err, test.err)
+			}
+		})
+	}
+}
+
+func TestUserConfig_BindUnsetPopupButton(t *testing.T) {
+	t.Parallel()
+
+	config := NewUserConfig(NewPopupButton("sort", "Sort").Option("Name", "name").Option("Size", "size"))
+
+	var got struct {
+		Sort string `alfred:"sort"`
+	}
+
+	if err := config.bind(&got, lookupMap(nil)); err != nil {
+		t.Errorf("got: %v want: nil", err)
+	}
+
+	config = NewUserConfig(NewPopupButton("sort", "Sort").Option("Name", "name").Required(true))
+	if err := config.bind(&got, lookupMap(nil)); !errors.Is(err, ErrConfigRequired) {
+		t.Errorf("got: %v want: %v", err, ErrConfigRequired)
+	}
+}
+
+func TestUserConfig_BindEnv(t *testing.T) {
+	t.Setenv("api_key", "from-env")
+
+	var got testSettings
+	if err := testConfig().Bind(&got); err != nil {
+		t.Fatalf("bind error: %v", err)
+	}
+
+	if got.APIKey != "from-env" {
+		t.Errorf("got: %s want: from-env", got.APIKey)
+	}
+}
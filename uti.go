@@ -0,0 +1,252 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UTI is a Uniform Type Identifier, as used by icons of type IconTypeFileType.
+type UTI string
+
+// common uniform type identifiers.
+const (
+	UTIItem        UTI = "public.item"
+	UTIData        UTI = "public.data"
+	UTIFolder      UTI = "public.folder"
+	UTIVolume      UTI = "public.volume"
+	UTISymlink     UTI = "public.symlink"
+	UTIExecutable  UTI = "public.unix-executable"
+	UTIApplication UTI = "com.apple.application-bundle"
+	UTIURL         UTI = "public.url"
+
+	UTIText          UTI = "public.text"
+	UTIPlainText     UTI = "public.plain-text"
+	UTIRTF           UTI = "public.rtf"
+	UTIHTML          UTI = "public.html"
+	UTIXML           UTI = "public.xml"
+	UTIJSON          UTI = "public.json"
+	UTIYAML          UTI = "public.yaml"
+	UTICSV           UTI = "public.comma-separated-values-text"
+	UTIMarkdown      UTI = "net.daringfireball.markdown"
+	UTISourceCode    UTI = "public.source-code"
+	UTIShellScript   UTI = "public.shell-script"
+	UTIPythonScript  UTI = "public.python-script"
+	UTIRubyScript    UTI = "public.ruby-script"
+	UTIJavaScript    UTI = "com.netscape.javascript-source"
+	UTISwiftSource   UTI = "public.swift-source"
+	UTICSource       UTI = "public.c-source"
+	UTICHeader       UTI = "public.c-header"
+	UTIAppleScript   UTI = "com.apple.applescript.text"
+	UTIPropertyList  UTI = "com.apple.property-list"
+	UTIVCard         UTI = "public.vcard"
+	UTICalendarEvent UTI = "com.apple.ical.ics"
+
+	UTIImage UTI = "public.image"
+	UTIPNG   UTI = "public.png"
+	UTIJPEG  UTI = "public.jpeg"
+	UTIGIF   UTI = "com.compuserve.gif"
+	UTITIFF  UTI = "public.tiff"
+	UTIHEIC  UTI = "public.heic"
+	UTIBMP   UTI = "com.microsoft.bmp"
+	UTIICNS  UTI = "com.apple.icns"
+	UTISVG   UTI = "public.svg-image"
+	UTIWebP  UTI = "org.webmproject.webp"
+
+	UTIAudio      UTI = "public.audio"
+	UTIMP3        UTI = "public.mp3"
+	UTIMPEG4Audio UTI = "public.mpeg-4-audio"
+	UTIWAV        UTI = "com.microsoft.waveform-audio"
+	UTIAIFF       UTI = "public.aiff-audio"
+	UTIFLAC       UTI = "org.xiph.flac"
+
+	UTIMovie     UTI = "public.movie"
+	UTIMPEG4     UTI = "public.mpeg-4"
+	UTIQuickTime UTI = "com.apple.quicktime-movie"
+	UTIAVI       UTI = "public.avi"
+
+	UTIArchive   UTI = "public.archive"
+	UTIZip       UTI = "public.zip-archive"
+	UTIGzip      UTI = "org.gnu.gnu-zip-archive"
+	UTIBzip2     UTI = "public.bzip2-archive"
+	UTITar       UTI = "public.tar-archive"
+	UTIDiskImage UTI = "com.apple.disk-image-udif"
+	UTIPackage   UTI = "com.apple.installer-package-archive"
+
+	UTIPDF        UTI = "com.adobe.pdf"
+	UTIWord       UTI = "com.microsoft.word.doc"
+	UTIWordX      UTI = "org.openxmlformats.wordprocessingml.document"
+	UTIExcel      UTI = "com.microsoft.excel.xls"
+	UTIExcelX     UTI = "org.openxmlformats.spreadsheetml.sheet"
+	UTIPowerPoint UTI = "com.microsoft.powerpoint.ppt"
+	UTIPowerPtX   UTI = "org.openxmlformats.presentationml.presentation"
+	UTIPages      UTI = "com.apple.iwork.pages.sffpages"
+	UTINumbers    UTI = "com.apple.iwork.numbers.sffnumbers"
+	UTIKeynote    UTI = "com.apple.iwork.keynote.sffkey"
+	UTIFont       UTI = "public.font"
+)
+
+// utiExtensions returns the UTI of common file extensions, in lower case without the dot.
+func utiExtensions() map[string]UTI {
+	return map[string]UTI{
+		"txt": UTIPlainText, "text": UTIPlainText, "log": UTIPlainText,
+		"rtf": UTIRTF, "html": UTIHTML, "htm": UTIHTML, "xml": UTIXML,
+		"json": UTIJSON, "yaml": UTIYAML, "yml": UTIYAML, "csv": UTICSV,
+		"md": UTIMarkdown, "markdown": UTIMarkdown,
+		"sh": UTIShellScript, "bash": UTIShellScript, "zsh": UTIShellScript,
+		"py": UTIPythonScript, "rb": UTIRubyScript, "js": UTIJavaScript,
+		"swift": UTISwiftSource, "c": UTICSource, "h": UTICHeader, "go": UTISourceCode,
+		"applescript": UTIAppleScript, "plist": UTIPropertyList,
+		"vcf": UTIVCard, "ics": UTICalendarEvent,
+		"png": UTIPNG, "jpg": UTIJPEG, "jpeg": UTIJPEG, "gif": UTIGIF,
+		"tif": UTITIFF, "tiff": UTITIFF, "heic": UTIHEIC, "bmp": UTIBMP,
+		"icns": UTIICNS, "svg": UTISVG, "webp": UTIWebP,
+		"mp3": UTIMP3, "m4a": UTIMPEG4Audio, "wav": UTIWAV, "aif": UTIAIFF,
+		"aiff": UTIAIFF, "flac": UTIFLAC,
+		"mp4": UTIMPEG4, "m4v": UTIMPEG4, "mov": UTIQuickTime, "avi": UTIAVI,
+		"zip": UTIZip, "gz": UTIGzip, "tgz": UTIGzip, "bz2": UTIBzip2, "tar": UTITar,
+		"dmg": UTIDiskImage, "pkg": UTIPackage,
+		"pdf": UTIPDF, "doc": UTIWord, "docx": UTIWordX, "xls": UTIExcel,
+		"xlsx": UTIExcelX, "ppt": UTIPowerPoint, "pptx": UTIPowerPtX,
+		"pages": UTIPages, "numbers": UTINumbers, "key": UTIKeynote,
+		"ttf": UTIFont, "otf": UTIFont,
+		"app": UTIApplication, "webloc": UTIURL,
+	}
+}
+
+// utiMIMETypes returns the UTI of common MIME types.
+func utiMIMETypes() map[string]UTI {
+	return map[string]UTI{
+		"text/plain":                    UTIPlainText,
+		"text/rtf":                      UTIRTF,
+		"application/rtf":               UTIRTF,
+		"text/html":                     UTIHTML,
+		"text/xml":                      UTIXML,
+		"application/xml":               UTIXML,
+		"application/json":              UTIJSON,
+		"application/yaml":              UTIYAML,
+		"text/csv":                      UTICSV,
+		"text/markdown":                 UTIMarkdown,
+		"application/x-sh":              UTIShellScript,
+		"text/x-python":                 UTIPythonScript,
+		"text/javascript":               UTIJavaScript,
+		"application/javascript":        UTIJavaScript,
+		"text/vcard":                    UTIVCard,
+		"text/calendar":                 UTICalendarEvent,
+		"image/png":                     UTIPNG,
+		"image/jpeg":                    UTIJPEG,
+		"image/gif":                     UTIGIF,
+		"image/tiff":                    UTITIFF,
+		"image/heic":                    UTIHEIC,
+		"image/bmp":                     UTIBMP,
+		"image/svg+xml":                 UTISVG,
+		"image/webp":                    UTIWebP,
+		"audio/mpeg":                    UTIMP3,
+		"audio/mp4":                     UTIMPEG4Audio,
+		"audio/wav":                     UTIWAV,
+		"audio/x-wav":                   UTIWAV,
+		"audio/aiff":                    UTIAIFF,
+		"audio/flac":                    UTIFLAC,
+		"video/mp4":                     UTIMPEG4,
+		"video/quicktime":               UTIQuickTime,
+		"video/x-msvideo":               UTIAVI,
+		"application/zip":               UTIZip,
+		"application/gzip":              UTIGzip,
+		"application/x-bzip2":           UTIBzip2,
+		"application/x-tar":             UTITar,
+		"application/x-apple-diskimage": UTIDiskImage,
+		"application/pdf":               UTIPDF,
+		"application/msword":            UTIWord,
+		"application/vnd.ms-excel":      UTIExcel,
+		"application/vnd.ms-powerpoint": UTIPowerPoint,
+		"font/ttf":                      UTIFont,
+		"font/otf":                      UTIFont,
+
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   UTIWordX,
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         UTIExcelX,
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation": UTIPowerPtX,
+	}
+}
+
+// UTIForExtension returns the UTI of a file extension such as ".pdf" or "pdf".
+func UTIForExtension(ext string) (UTI, bool) {
+	uti, ok := utiExtensions()[strings.ToLower(strings.TrimPrefix(ext, "."))]
+
+	return uti, ok
+}
+
+// UTIForMIMEType returns the UTI of a MIME type such as "application/pdf".
+// Parameters are ignored and unknown image, audio, video and text types
+// return the UTI of their category.
+func UTIForMIMEType(mime string) (UTI, bool) {
+	if i := strings.IndexByte(mime, ';'); i >= 0 {
+		mime = mime[:i]
+	}
+
+	mime = strings.ToLower(strings.TrimSpace(mime))
+
+	if uti, ok := utiMIMETypes()[mime]; ok {
+		return uti, true
+	}
+
+	switch {
+	case strings.HasPrefix(mime, "image/"):
+		return UTIImage, true
+	case strings.HasPrefix(mime, "audio/"):
+		return UTIAudio, true
+	case strings.HasPrefix(mime, "video/"):
+		return UTIMovie, true
+	case strings.HasPrefix(mime, "text/"):
+		return UTIText, true
+	default:
+		return "", false
+	}
+}
+
+// Icon returns an Icon of type IconTypeFileType showing the icon of the UTI.
+func (u UTI) Icon() *Icon {
+	return NewIconWithType(string(u), IconTypeFileType)
+}
+
+// IconForExtension returns the icon of files with the extension,
+// or of generic documents when the extension is unknown.
+func IconForExtension(ext string) *Icon {
+	uti, ok := UTIForExtension(ext)
+	if !ok {
+		uti = UTIData
+	}
+
+	return uti.Icon()
+}
+
+// IconForMIMEType returns the icon of files of the MIME type,
+// or of generic documents when the type is unknown.
+func IconForMIMEType(mime string) *Icon {
+	uti, ok := UTIForMIMEType(mime)
+	if !ok {
+		uti = UTIData
+	}
+
+	return uti.Icon()
+}
+
+// IconForPath returns the icon of the file at path.
+//
+// An existing file uses IconTypeFileIcon so Alfred shows its own icon, such as
+// the icon of an application or a custom folder icon. Otherwise the icon is
+// chosen from the extension, or is a folder icon when path ends with a slash.
+func IconForPath(path string) *Icon {
+	if _, err := os.Stat(path); err == nil {
+		return NewIconWithType(path, IconTypeFileIcon)
+	}
+
+	if strings.HasSuffix(path, "/") {
+		return UTIFolder.Icon()
+	}
+
+	return IconForExtension(filepath.Ext(path))
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestUTIForExtension(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in  string
+		out UTI
+		ok  bool
+	}
+
+	tests := []Test{
+		{in: ".pdf", out: UTIPDF, ok: true},
+		{in: "pdf", out: UTIPDF, ok: true},
+		{in: ".JPG", out: UTIJPEG, ok: true},
+		{in: ".docx", out: UTIWordX, ok: true},
+		{in: ".unknown", out: "", ok: false},
+		{in: "", out: "", ok: false},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:UTIForExtension", i), func(t *testing.T) {
+			t.Parallel()
+			if got, ok := UTIForExtension(test.in); got != test.out || ok != test.ok {
+				t.Errorf("#%d: got: %s, %t want: %s, %t", i, got, ok, test.out, test.ok)
+			}
+		})
+	}
+}
+
+func TestUTIForMIMEType(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in  string
+		out UTI
+		ok  bool
+	}
+
+	tests := []Test{
+		{in: "application/pdf", out: UTIPDF, ok: true},
+		{in: "text/html; charset=utf-8", out: UTIHTML, ok: true},
+		{in: "IMAGE/PNG", out: UTIPNG, ok: true},
+		{in: "image/x-unknown", out: UTIImage, ok: true},
+		{in: "audio/ogg", out: UTIAudio, ok: true},
+		{in: "video/webm", out: UTIMovie, ok: true},
+		{in: "text/x-unknown", out: UTIText, ok: true},
+		{in: "application/octet-stream", out: "", ok: false},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:UTIForMIMEType", i), func(t *testing.T) {
+			t.Parallel()
+			if got, ok := UTIForMIMEType(test.in); got != test.out || ok != test.ok {
+				t.Errorf("#%d: got: %s, %t want: %s, %t", i, got, ok, test.out, test.ok)
+			}
+		})
+	}
+}
+
+func TestIconForPath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"report.pdf": "%PDF"})
+
+	type Test struct {
+		in  *Icon
+		out string
+	}
+
+	tests := []Test{
+		{in: IconForPath(filepath.Join(dir, "report.pdf")), out: `{"path":"` + filepath.Join(dir, "report.pdf") + `","type":"fileicon"}`},
+		{in: IconForPath(dir), out: `{"path":"` + dir + `","type":"fileicon"}`},
+		{in: IconForPath("/missing/notes.md"), out: `{"path":"net.daringfireball.markdown","type":"filetype"}`},
+		{in: IconForPath("/missing/folder/"), out: `{"path":"public.folder","type":"filetype"}`},
+		{in: IconForPath("/missing/file"), out: `{"path":"public.data","type":"filetype"}`},
+		{in: IconForExtension(".zip"), out: `{"path":"public.zip-archive","type":"filetype"}`},
+		{in: IconForMIMEType("application/x-unknown"), out: `{"path":"public.data","type":"filetype"}`},
+		{in: IconForMIMEType("video/mp4"), out: `{"path":"public.mpeg-4","type":"filetype"}`},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:IconForPath", i), func(t *testing.T) {
+			t.Parallel()
+			testMarshalJSON(t, i, test.in, test.out)
+		})
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// version errors.
+var (
+	ErrInvalidVersion    = errors.New("invalid version")
+	ErrInvalidConstraint = errors.New("invalid version constraint")
+)
+
+// Version represents a semantic version as described by https://semver.org.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	Build      string
+}
+
+// ParseVersion parses a version such as "5.1.2", "v1.2.0-beta.1" or "1.0+20210306".
+//
+// A leading "v" is ignored and missing minor and patch numbers are zero,
+// so "5" and "5.1" are accepted as Alfred reports them.
+func ParseVersion(s string) (Version, error) {
+	var v Version
+
+	rest := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "v"), "V")
+
+	if i := strings.IndexByte(rest, '+'); i >= 0 {
+		rest, v.Build = rest[:i], rest[i+1:]
+		if !validIdentifiers(v.Build, false) {
+			return Version{}, fmt.Errorf("%w: %q: build %q", ErrInvalidVersion, s, v.Build)
+		}
+	}
+
+	if i := strings.IndexByte(rest, '-'); i >= 0 {
+		rest, v.Prerelease = rest[:i], rest[i+1:]
+		if !validIdentifiers(v.Prerelease, true) {
+			return Version{}, fmt.Errorf("%w: %q: prerelease %q", ErrInvalidVersion, s, v.Prerelease)
+		}
+	}
+
+	const maxParts = 3
+
+	parts := strings.Split(rest, ".")
+	if len(parts) > maxParts {
+		return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+	}
+
+	nums := [maxParts]int{}
+
+	for i, p := range parts {
+		if !isNumeric(p) || (len(p) > 1 && p[0] == '0') {
+			return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+		}
+
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return Version{}, fmt.Errorf("%w: %q: %s", ErrInvalidVersion, s, err.Error())
+		}
+
+		nums[i] = n
+	}
+
+	v.Major, v.Minor, v.Patch = nums[0], nums[1], nums[2]
+
+	return v, nil
+}
+
+// MustParseVersion is like ParseVersion but panics if s cannot be parsed.
+func MustParseVersion(s string) Version {
+	v, err := ParseVersion(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// String returns the version in its canonical form, without a leading "v".
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+
+	return s
+}
+
+// IsPrerelease reports whether v is a prerelease.
+func (v Version) IsPrerelease() bool {
+	return v.Prerelease != ""
+}
+
+// Compare returns -1, 0 or +1 depending on whether v has a lower, equal or
+// higher precedence than o. Build metadata is ignored.
+func (v Version) Compare(o Version) int {
+	if c := compareInt(v.Major, o.Major); c != 0 {
+		return c
+	}
+
+	if c := compareInt(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+
+	if c := compareInt(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// LessThan reports whether v has a lower precedence than o.
+func (v Version) LessThan(o Version) bool {
+	return v.Compare(o) < 0
+}
+
+// AtLeast reports whether v has a precedence equal to or higher than o.
+func (v Version) AtLeast(o Version) bool {
+	return v.Compare(o) >= 0
+}
+
+// Supports reports whether Alfred at version v supports the feature f.
+func (v Version) Supports(f Feature) bool {
+	return v.AtLeast(f.Since())
+}
+
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := compareIdentifier(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+
+	return compareInt(len(as), len(bs))
+}
+
+func compareIdentifier(a, b string) int {
+	an, bn := isNumeric(a), isNumeric(b)
+
+	switch {
+	case an && bn:
+		if c := compareInt(len(a), len(b)); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a, b)
+	case an:
+		return -1
+	case bn:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validIdentifiers reports whether s is a dot separated list of non-empty
+// alphanumeric identifiers; numeric prerelease identifiers have no leading zero.
+func validIdentifiers(s string, prerelease bool) bool {
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return false
+		}
+
+		for _, r := range id {
+			if !(r == '-' || '0' <= r && r <= '9' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z') {
+				return false
+			}
+		}
+
+		if prerelease && len(id) > 1 && id[0] == '0' && isNumeric(id) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Constraint is a set of comparisons all versions matching it satisfy,
+// such as ">=5.0, <6".
+type Constraint struct {
+	raw   string
+	terms []constraintTerm
+}
+
+type constraintTerm struct {
+	op      string
+	version Version
+}
+
+// ParseConstraint parses a comma separated list of comparisons.
+//
+// Supported operators are =, !=, >, >=, <, <=, ~ (same minor version) and
+// ^ (same major version); a version without an operator must be equal.
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{raw: strings.TrimSpace(s)}
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return Constraint{}, fmt.Errorf("%w: %q", ErrInvalidConstraint, s)
+		}
+
+		op := ""
+
+		for _, o := range []string{">=", "<=", "!=", "==", ">", "<", "=", "~", "^"} {
+			if strings.HasPrefix(part, o) {
+				op = o
+
+				break
+			}
+		}
+
+		v, err := ParseVersion(strings.TrimSpace(part[len(op):]))
+		if err != nil {
+			return Constraint{}, fmt.Errorf("%w: %q: %s", ErrInvalidConstraint, s, err.Error())
+		}
+
+		if op == "" || op == "==" {
+			op = "="
+		}
+
+		c.terms = append(c.terms, constraintTerm{op: op, version: v})
+	}
+
+	return c, nil
+}
+
+// MustParseConstraint is like ParseConstraint but panics if s cannot be parsed.
+func MustParseConstraint(s string) Constraint {
+	c, err := ParseConstraint(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// String returns the constraint as it was parsed.
+func (c Constraint) String() string {
+	return c.raw
+}
+
+// Check reports whether v satisfies every comparison of the constraint.
+func (c Constraint) Check(v Version) bool {
+	for _, t := range c.terms {
+		if !t.check(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t constraintTerm) check(v Version) bool {
+	cmp := v.Compare(t.version)
+
+	switch t.op {
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "~":
+		return cmp >= 0 && v.Major == t.version.Major && v.Minor == t.version.Minor
+	case "^":
+		return cmp >= 0 && v.Major == t.version.Major
+	default:
+		return cmp == 0
+	}
+}
+
+// Feature is a Script Filter feature only available in some Alfred versions.
+type Feature string
+
+// features.
+const (
+	FeatureItemAction        Feature = "item action"
+	FeatureSkipKnowledge     Feature = "skipknowledge"
+	FeatureScriptFilterCache Feature = "cache"
+)
+
+// Since returns the first Alfred version supporting the feature.
+func (f Feature) Since() Version {
+	switch f {
+	case FeatureItemAction:
+		return Version{Major: 4, Minor: 5} //nolint:gomnd // Alfred 4.5
+	case FeatureSkipKnowledge:
+		return Version{Major: 5} //nolint:gomnd // Alfred 5.0
+	case FeatureScriptFilterCache:
+		return Version{Major: 5, Minor: 5} //nolint:gomnd // Alfred 5.5
+	default:
+		return Version{}
+	}
+}
+
+// AlfredVersion returns the version of the running Alfred, with its build
+// number as build metadata. It returns false outside Alfred.
+func AlfredVersion() (Version, bool) {
+	v, err := ParseVersion(os.Getenv(EnvAlfredVersion))
+	if err != nil {
+		return Version{}, false
+	}
+
+	if build := os.Getenv(EnvAlfredVersionBuild); validIdentifiers(build, false) {
+		v.Build = build
+	}
+
+	return v, true
+}
+
+// AlfredSupports reports whether the running Alfred supports the feature f.
+// Outside Alfred, every feature is assumed to be supported.
+func AlfredSupports(f Feature) bool {
+	v, ok := AlfredVersion()
+
+	return !ok || v.Supports(f)
+}
@@ -0,0 +1,193 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in  string
+		out Version
+		err error
+	}
+
+	tests := []Test{
+		{in: "5.1.2", out: Version{Major: 5, Minor: 1, Patch: 2}},
+		{in: "v1.2.0-beta.1", out: Version{Major: 1, Minor: 2, Prerelease: "beta.1"}},
+		{in: " 5 ", out: Version{Major: 5}},
+		{in: "5.1", out: Version{Major: 5, Minor: 1}},
+		{in: "1.0.0-rc.1+build.2145", out: Version{Major: 1, Prerelease: "rc.1", Build: "build.2145"}},
+		{in: "1.0+20210306", out: Version{Major: 1, Build: "20210306"}},
+		{in: "", err: ErrInvalidVersion},
+		{in: "1.2.3.4", err: ErrInvalidVersion},
+		{in: "1.x", err: ErrInvalidVersion},
+		{in: "1.0.0-", err: ErrInvalidVersion},
+		{in: "1.0.0-01", err: ErrInvalidVersion},
+		{in: "01.2.3", err: ErrInvalidVersion},
+		{in: "1.02.3", err: ErrInvalidVersion},
+		{in: "1.2.00", err: ErrInvalidVersion},
+		{in: "1.0.0-a..b", err: ErrInvalidVersion},
+		{in: "1.0.0+b_1", err: ErrInvalidVersion},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:ParseVersion", i), func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseVersion(test.in)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("#%d: got: %v want: %v", i, err, test.err)
+			}
+			if got != test.out {
+				t.Errorf("#%d: got: %+v want: %+v", i, got, test.out)
+			}
+		})
+	}
+}
+
+func TestVersion_String(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in  string
+		out string
+	}
+
+	tests := []Test{
+		{in: "v5", out: "5.0.0"},
+		{in: "1.2.3-beta.1", out: "1.2.3-beta.1"},
+		{in: "1.2.3-beta+2145", out: "1.2.3-beta+2145"},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:String", i), func(t *testing.T) {
+			t.Parallel()
+			if got := MustParseVersion(test.in).String(); got != test.out {
+				t.Errorf("#%d: got: %s want: %s", i, got, test.out)
+			}
+		})
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		a   string
+		b   string
+		out int
+	}
+
+	// precedence examples of the SemVer 2.0 specification.
+	tests := []Test{
+		{a: "1.0.0", b: "2.0.0", out: -1},
+		{a: "2.0.0", b: "2.1.0", out: -1},
+		{a: "2.1.0", b: "2.1.1", out: -1},
+		{a: "1.0.0-alpha", b: "1.0.0", out: -1},
+		{a: "1.0.0-alpha", b: "1.0.0-alpha.1", out: -1},
+		{a: "1.0.0-alpha.1", b: "1.0.0-alpha.beta", out: -1},
+		{a: "1.0.0-alpha.beta", b: "1.0.0-beta", out: -1},
+		{a: "1.0.0-beta", b: "1.0.0-beta.2", out: -1},
+		{a: "1.0.0-beta.2", b: "1.0.0-beta.11", out: -1},
+		{a: "1.0.0-beta.11", b: "1.0.0-rc.1", out: -1},
+		{a: "1.0.0-rc.1", b: "1.0.0", out: -1},
+		{a: "1.10.0", b: "1.9.0", out: 1},
+		{a: "1.0.0+2145", b: "1.0.0+2100", out: 0},
+		{a: "v1.0", b: "1.0.0", out: 0},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Compare", i), func(t *testing.T) {
+			t.Parallel()
+			a, b := MustParseVersion(test.a), MustParseVersion(test.b)
+			if got := a.Compare(b); got != test.out {
+				t.Errorf("#%d: got: %d want: %d", i, got, test.out)
+			}
+			if got := b.Compare(a); got != -test.out {
+				t.Errorf("#%d: reversed: got: %d want: %d", i, got, -test.out)
+			}
+		})
+	}
+}
+
+func TestConstraint_Check(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		constraint string
+		version    string
+		out        bool
+	}
+
+	tests := []Test{
+		{constraint: ">=5.0, <6", version: "5.1.2", out: true},
+		{constraint: ">=5.0, <6", version: "6.0.0", out: false},
+		{constraint: ">=5.0, <6", version: "4.8", out: false},
+		{constraint: ">=5.0, <6", version: "6.0.0-beta", out: true},
+		{constraint: "5.1.2", version: "v5.1.2", out: true},
+		{constraint: "==5.1.2", version: "5.1.3", out: false},
+		{constraint: "!=5.1.2", version: "5.1.3", out: true},
+		{constraint: ">4.5", version: "4.5.0", out: false},
+		{constraint: "<=4.5", version: "4.5.0", out: true},
+		{constraint: "~4.5", version: "4.5.9", out: true},
+		{constraint: "~4.5", version: "4.6.0", out: false},
+		{constraint: "^4.5", version: "4.8.1", out: true},
+		{constraint: "^4.5", version: "5.0.0", out: false},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Check", i), func(t *testing.T) {
+			t.Parallel()
+			c := MustParseConstraint(test.constraint)
+			if got := c.Check(MustParseVersion(test.version)); got != test.out {
+				t.Errorf("#%d: got: %t want: %t", i, got, test.out)
+			}
+		})
+	}
+}
+
+func TestParseConstraint_Error(t *testing.T) {
+	t.Parallel()
+
+	for i, in := range []string{"", ">=5.0,", ">=five", "=>5"} {
+		if _, err := ParseConstraint(in); !errors.Is(err, ErrInvalidConstraint) {
+			t.Errorf("#%d: got: %v want: %v", i, err, ErrInvalidConstraint)
+		}
+	}
+}
+
+func TestAlfredVersion(t *testing.T) {
+	t.Setenv(EnvAlfredVersion, "")
+
+	if _, ok := AlfredVersion(); ok {
+		t.Errorf("got a version outside Alfred")
+	}
+
+	if !AlfredSupports(FeatureSkipKnowledge) {
+		t.Errorf("features are not supported outside Alfred")
+	}
+
+	t.Setenv(EnvAlfredVersion, "4.6.1")
+	t.Setenv(EnvAlfredVersionBuild, "1274")
+
+	v, ok := AlfredVersion()
+	if want := (Version{Major: 4, Minor: 6, Patch: 1, Build: "1274"}); !ok || v != want {
+		t.Errorf("got: %+v, %t want: %+v", v, ok, want)
+	}
+
+	if !AlfredSupports(FeatureItemAction) || AlfredSupports(FeatureSkipKnowledge) {
+		t.Errorf("got: item action %t, skipknowledge %t want: true, false",
+			AlfredSupports(FeatureItemAction), AlfredSupports(FeatureSkipKnowledge))
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultWizardPrefix is the prefix of the variables holding the answers of a Wizard.
+const DefaultWizardPrefix = "wizard_"
+
+// wizardLabelSeparator ends the label of a step in the query.
+const wizardLabelSeparator = ": "
+
+// WizardStep is an input collected by a Wizard.
+type WizardStep struct {
+	key      string
+	label    string
+	prompt   string
+	validate func(string) error
+}
+
+// NewWizardStep returns a WizardStep storing its answer under key, labeled with key.
+func NewWizardStep(key, prompt string) *WizardStep {
+	return &WizardStep{key: key, label: key, prompt: prompt}
+}
+
+// Label sets the label marking the step in the query, such as "Date".
+func (s *WizardStep) Label(label string) *WizardStep {
+	s.label = label
+
+	return s
+}
+
+// Validate sets the function checking answers. Its error is shown to the user.
+func (s *WizardStep) Validate(validate func(answer string) error) *WizardStep {
+	s.validate = validate
+
+	return s
+}
+
+func (s *WizardStep) check(answer string) error {
+	if s.validate == nil {
+		return nil
+	}
+
+	return s.validate(answer)
+}
+
+// Wizard collects several inputs one after the other in a single Script Filter.
+//
+// The query is the answer of the current step, after the label of the step
+// such as "Date: 2021-03-04". A valid answer is stored in a variable of the
+// ScriptFilter (see ScriptFilter.Variables) and its item autocompletes to the
+// label of the next step. Once the last step is answered, the answers are
+// handed to the complete callback, which renders the final items.
+type Wizard struct {
+	steps    []*WizardStep
+	prefix   string
+	complete func(answers map[string]string, sf *ScriptFilter)
+}
+
+// NewWizard returns a Wizard with the given steps.
+func NewWizard(steps ...*WizardStep) *Wizard {
+	return &Wizard{steps: steps, prefix: DefaultWizardPrefix}
+}
+
+// Prefix sets the prefix of the variables holding the answers.
+func (w *Wizard) Prefix(prefix string) *Wizard {
+	w.prefix = prefix
+
+	return w
+}
+
+// OnComplete sets the function rendering the items once every step is answered,
+// typically a valid item whose variables are the answers.
+func (w *Wizard) OnComplete(complete func(answers map[string]string, sf *ScriptFilter)) *Wizard {
+	w.complete = complete
+
+	return w
+}
+
+// Answers returns the valid answers passed back by Alfred, by step key.
+func (w *Wizard) Answers() map[string]string {
+	return w.answers(os.LookupEnv)
+}
+
+// Render appends to sf the items of the current step and stores the answers
+// in its variables.
+func (w *Wizard) Render(query string, sf *ScriptFilter) {
+	w.render(query, sf, os.LookupEnv)
+}
+
+func (w *Wizard) answers(lookup func(string) (string, bool)) map[string]string {
+	answers := make(map[string]string)
+
+	for _, s := range w.steps {
+		if v, ok := lookup(w.prefix + s.key); ok && s.check(v) == nil {
+			answers[s.key] = v
+		}
+	}
+
+	return answers
+}
+
+func (w *Wizard) render(query string, sf *ScriptFilter, lookup func(string) (string, bool)) {
+	if len(w.steps) == 0 {
+		return
+	}
+
+	answers := w.answers(lookup)
+	for key, v := range answers {
+		sf.Variables().Put(w.prefix+key, v)
+	}
+
+	current, answer := w.step(query)
+
+	// An earlier step without answer, when its label is typed directly, comes first.
+	for i := 0; i < current; i++ {
+		if _, ok := answers[w.steps[i].key]; !ok {
+			current, answer = i, ""
+
+			break
+		}
+	}
+
+	step := w.steps[current]
+	progress := fmt.Sprintf("Step %d of %d", current+1, len(w.steps))
+
+	if strings.TrimSpace(answer) == "" {
+		sf.Items().Append(NewInvalidItem(step.prompt).
+			Subtitle(progress).
+			Autocomplete(w.query(current)))
+
+		return
+	}
+
+	if err := step.check(answer); err != nil {
+		sf.Items().Append(NewInvalidItem(answer).
+			Subtitle(err.Error()).
+			Autocomplete(w.query(current) + answer).
+			Icon(IconAlertStop))
+
+		return
+	}
+
+	answers[step.key] = answer
+	sf.Variables().Put(w.prefix+step.key, answer)
+
+	if current+1 < len(w.steps) {
+		next := w.steps[current+1]
+
+		sf.Items().Append(NewInvalidItem(answer).
+			Subtitle(fmt.Sprintf("%s · Press ↩ to continue: %s", progress, next.prompt)).
+			Autocomplete(w.query(current + 1)))
+
+		return
+	}
+
+	if w.complete != nil {
+		w.complete(answers, sf)
+	}
+}
+
+// step returns the index of the step labeled in query and the answer after the label.
+func (w *Wizard) step(query string) (int, string) {
+	for i, s := range w.steps {
+		label := s.label + wizardLabelSeparator
+		if len(query) >= len(label) && strings.EqualFold(query[:len(label)], label) {
+			return i, query[len(label):]
+		}
+
+		if strings.EqualFold(query, strings.TrimSpace(label)) {
+			return i, ""
+		}
+	}
+
+	return 0, query
+}
+
+// query returns the query of the step at index i.
+func (w *Wizard) query(i int) string {
+	return w.steps[i].label + wizardLabelSeparator
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var errTestDate = errors.New("use the YYYY-MM-DD format")
+
+func testWizard(done *map[string]string) *Wizard {
+	return NewWizard(
+		NewWizardStep("name", "Enter a name").Label("Name"),
+		NewWizardStep("date", "Enter a date").Label("Date").Validate(func(s string) error {
+			if _, err := time.Parse("2006-01-02", s); err != nil {
+				return errTestDate
+			}
+
+			return nil
+		}),
+		NewWizardStep("tag", "Enter a tag").Label("Tag"),
+	).OnComplete(func(answers map[string]string, sf *ScriptFilter) {
+		*done = answers
+
+		sf.Items().Append(NewItem("Create " + answers["name"]))
+	})
+}
+
+func TestWizard_Render(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		query string
+		env   map[string]string
+		items Items
+		vars  Variables
+	}
+
+	tests := []Test{
+		{
+			query: "",
+			items: Items{NewInvalidItem("Enter a name").Subtitle("Step 1 of 3").Autocomplete("Name: ")},
+			vars:  Variables{},
+		},
+		{
+			query: "Name: Bob",
+			items: Items{
+				NewInvalidItem("Bob").Subtitle("Step 1 of 3 · Press ↩ to continue: Enter a date").Autocomplete("Date: "),
+			},
+			vars: Variables{"wizard_name": "Bob"},
+		},
+		{
+			query: "Bob",
+			items: Items{
+				NewInvalidItem("Bob").Subtitle("Step 1 of 3 · Press ↩ to continue: Enter a date").Autocomplete("Date: "),
+			},
+			vars: Variables{"wizard_name": "Bob"},
+		},
+		{
+			query: "Date: ",
+			env:   map[string]string{"wizard_name": "Bob"},
+			items: Items{NewInvalidItem("Enter a date").Subtitle("Step 2 of 3").Autocomplete("Date: ")},
+			vars:  Variables{"wizard_name": "Bob"},
+		},
+		{
+			query: "date: tomorrow",
+			env:   map[string]string{"wizard_name": "Bob"},
+			items: Items{
+				NewInvalidItem("tomorrow").Subtitle(errTestDate.Error()).Autocomplete("Date: tomorrow").Icon(IconAlertStop),
+			},
+			vars: Variables{"wizard_name": "Bob"},
+		},
+		{
+			query: "Tag: x",
+			env:   map[string]string{"wizard_name": "Bob"},
+			items: Items{NewInvalidItem("Enter a date").Subtitle("Step 2 of 3").Autocomplete("Date: ")},
+			vars:  Variables{"wizard_name": "Bob"},
+		},
+		{
+			query: "Date: 2021-03-04",
+			env:   map[string]string{"wizard_name": "Bob", "wizard_date": "bad"},
+			items: Items{
+				NewInvalidItem("2021-03-04").Subtitle("Step 2 of 3 · Press ↩ to continue: Enter a tag").Autocomplete("Tag: "),
+			},
+			vars: Variables{"wizard_name": "Bob", "wizard_date": "2021-03-04"},
+		},
+		{
+			query: "Tag: work",
+			env:   map[string]string{"wizard_name": "Bob", "wizard_date": "2021-03-04"},
+			items: Items{NewItem("Create Bob")},
+			vars:  Variables{"wizard_name": "Bob", "wizard_date": "2021-03-04", "wizard_tag": "work"},
+		},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:Render", i), func(t *testing.T) {
+			t.Parallel()
+
+			var done map[string]string
+
+			sf := NewScriptFilter()
+			testWizard(&done).render(test.query, sf, lookupMap(test.env))
+
+			testMarshalJSON(t, i, *sf.Items(), testItemsJSON(t, test.items))
+
+			if got := *sf.Variables(); !reflect.DeepEqual(got, test.vars) {
+				t.Errorf("#%d: got: %v want: %v", i, got, test.vars)
+			}
+		})
+	}
+}
+
+func TestWizard_OnComplete(t *testing.T) {
+	t.Parallel()
+
+	var done map[string]string
+
+	env := map[string]string{"wizard_name": "Bob", "wizard_date": "2021-03-04"}
+	testWizard(&done).render("Tag: work", NewScriptFilter(), lookupMap(env))
+
+	want := map[string]string{"name": "Bob", "date": "2021-03-04", "tag": "work"}
+	if !reflect.DeepEqual(done, want) {
+		t.Errorf("got: %v want: %v", done, want)
+	}
+}
+
+func TestWizard_Answers(t *testing.T) {
+	t.Setenv("todo_name", "Bob")
+	t.Setenv("todo_date", "soon")
+
+	var done map[string]string
+
+	got := testWizard(&done).Prefix("todo_").Answers()
+	if want := map[string]string{"name": "Bob"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v want: %v", got, want)
+	}
+}
@@ -0,0 +1,717 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ModifierKey is the modifier key bit mask of a workflow connection.
+type ModifierKey int
+
+// modifier keys.
+const (
+	ModifierNone  ModifierKey = 0
+	ModifierShift ModifierKey = 1 << 17
+	ModifierCtrl  ModifierKey = 1 << 18
+	ModifierAlt   ModifierKey = 1 << 19
+	ModifierCmd   ModifierKey = 1 << 20
+	ModifierFn    ModifierKey = 1 << 23
+)
+
+// ArgumentType describes whether an input object requires an argument.
+type ArgumentType int
+
+// argument types.
+const (
+	ArgumentRequired ArgumentType = 0
+	ArgumentOptional ArgumentType = 1
+	ArgumentNone     ArgumentType = 2
+)
+
+// ScriptLanguage is the language a script object runs with.
+type ScriptLanguage int
+
+// script languages.
+const (
+	ScriptBash         ScriptLanguage = 0
+	ScriptPHP          ScriptLanguage = 1
+	ScriptRuby         ScriptLanguage = 2
+	ScriptPython       ScriptLanguage = 3
+	ScriptPerl         ScriptLanguage = 4
+	ScriptZsh          ScriptLanguage = 5
+	ScriptAppleScript  ScriptLanguage = 6
+	ScriptJavaScript   ScriptLanguage = 7
+	ScriptExternalFile ScriptLanguage = 8
+)
+
+// ConditionMatchMode is how a Conditional object compares its input.
+type ConditionMatchMode int
+
+// condition match modes.
+const (
+	MatchIsEqualTo     ConditionMatchMode = 0
+	MatchIsNotEqualTo  ConditionMatchMode = 1
+	MatchIsGreaterThan ConditionMatchMode = 2
+	MatchIsLessThan    ConditionMatchMode = 3
+	MatchRegex         ConditionMatchMode = 4
+)
+
+// layout of objects added without an explicit position.
+const (
+	layoutOrigin  = 50
+	layoutSpacing = 200
+)
+
+// Object represents an object of a workflow built with Workflow.
+type Object interface {
+	// UID returns the unique identifier of the object within the workflow.
+	UID() string
+	plist() map[string]interface{}
+}
+
+type object struct {
+	uid     string
+	typ     string
+	version int
+	config  map[string]interface{}
+}
+
+func newObject(typ string, version int, config map[string]interface{}) *object {
+	return &object{
+		uid:     newObjectUID(),
+		typ:     typ,
+		version: version,
+		config:  config,
+	}
+}
+
+// UID returns the unique identifier of the object within the workflow.
+func (o *object) UID() string {
+	return o.uid
+}
+
+func (o *object) plist() map[string]interface{} {
+	return map[string]interface{}{
+		"config":  o.config,
+		"type":    o.typ,
+		"uid":     o.uid,
+		"version": o.version,
+	}
+}
+
+// newObjectUID returns a random upper case UUID as used by Alfred for workflow objects.
+func newObjectUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("alfred: generate object uid: %v", err))
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40 //nolint:gomnd // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 //nolint:gomnd // variant 10
+
+	return fmt.Sprintf("%X-%X-%X-%X-%X", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// KeywordObject represents a Keyword input.
+type KeywordObject struct {
+	*object
+}
+
+// NewKeywordObject returns a Keyword input triggered by keyword.
+func NewKeywordObject(keyword string) *KeywordObject {
+	return &KeywordObject{newObject("alfred.workflow.input.keyword", 1, map[string]interface{}{
+		"argumenttype": int(ArgumentOptional),
+		"keyword":      keyword,
+		"subtext":      "",
+		"text":         "",
+		"withspace":    true,
+	})}
+}
+
+// Title sets the title shown in Alfred.
+func (o *KeywordObject) Title(title string) *KeywordObject {
+	o.config["text"] = title
+
+	return o
+}
+
+// Subtext sets the subtext shown in Alfred.
+func (o *KeywordObject) Subtext(subtext string) *KeywordObject {
+	o.config["subtext"] = subtext
+
+	return o
+}
+
+// ArgumentType sets whether an argument is required.
+func (o *KeywordObject) ArgumentType(typ ArgumentType) *KeywordObject {
+	o.config["argumenttype"] = int(typ)
+
+	return o
+}
+
+// ScriptFilterObject represents a Script Filter input.
+type ScriptFilterObject struct {
+	*object
+}
+
+// NewScriptFilterObject returns a Script Filter input triggered by keyword running script with bash.
+func NewScriptFilterObject(keyword, script string) *ScriptFilterObject {
+	return &ScriptFilterObject{newObject("alfred.workflow.input.scriptfilter", 3, map[string]interface{}{ //nolint:gomnd // object version
+		"alfredfiltersresults":           false,
+		"argumenttype":                   int(ArgumentOptional),
+		"escaping":                       int(EscapeBashDefault),
+		"keyword":                        keyword,
+		"queuedelaycustom":               3, //nolint:gomnd // Alfred default
+		"queuedelayimmediatelyinitially": true,
+		"queuedelaymode":                 0,
+		"queuemode":                      1,
+		"runningsubtext":                 "",
+		"script":                         script,
+		"scriptargtype":                  1,
+		"scriptfile":                     "",
+		"subtext":                        "",
+		"title":                          "",
+		"type":                           int(ScriptBash),
+		"withspace":                      true,
+	})}
+}
+
+// Title sets the title shown in Alfred.
+func (o *ScriptFilterObject) Title(title string) *ScriptFilterObject {
+	o.config["title"] = title
+
+	return o
+}
+
+// Subtext sets the subtext shown in Alfred.
+func (o *ScriptFilterObject) Subtext(subtext string) *ScriptFilterObject {
+	o.config["subtext"] = subtext
+
+	return o
+}
+
+// RunningSubtext sets the subtext shown while the script runs.
+func (o *ScriptFilterObject) RunningSubtext(subtext string) *ScriptFilterObject {
+	o.config["runningsubtext"] = subtext
+
+	return o
+}
+
+// ArgumentType sets whether an argument is required.
+func (o *ScriptFilterObject) ArgumentType(typ ArgumentType) *ScriptFilterObject {
+	o.config["argumenttype"] = int(typ)
+
+	return o
+}
+
+// Language sets the language of the script.
+func (o *ScriptFilterObject) Language(lang ScriptLanguage) *ScriptFilterObject {
+	o.config["type"] = int(lang)
+
+	return o
+}
+
+// Escaping sets the escaping applied to the query.
+func (o *ScriptFilterObject) Escaping(escaping EscapeFlags) *ScriptFilterObject {
+	o.config["escaping"] = int(escaping)
+
+	return o
+}
+
+// AlfredFiltersResults sets whether Alfred filters the results instead of the script.
+func (o *ScriptFilterObject) AlfredFiltersResults(filters bool) *ScriptFilterObject {
+	o.config["alfredfiltersresults"] = filters
+
+	return o
+}
+
+// RunScriptObject represents a Run Script action.
+type RunScriptObject struct {
+	*object
+}
+
+// NewRunScriptObject returns a Run Script action running script with bash.
+func NewRunScriptObject(script string) *RunScriptObject {
+	return &RunScriptObject{newObject("alfred.workflow.action.script", 2, map[string]interface{}{ //nolint:gomnd // object version
+		"concurrently":  false,
+		"escaping":      int(EscapeBashDefault),
+		"script":        script,
+		"scriptargtype": 1,
+		"scriptfile":    "",
+		"type":          int(ScriptBash),
+	})}
+}
+
+// Language sets the language of the script.
+func (o *RunScriptObject) Language(lang ScriptLanguage) *RunScriptObject {
+	o.config["type"] = int(lang)
+
+	return o
+}
+
+// Escaping sets the escaping applied to the argument.
+func (o *RunScriptObject) Escaping(escaping EscapeFlags) *RunScriptObject {
+	o.config["escaping"] = int(escaping)
+
+	return o
+}
+
+// Concurrently sets whether multiple instances of the script may run at once.
+func (o *RunScriptObject) Concurrently(concurrently bool) *RunScriptObject {
+	o.config["concurrently"] = concurrently
+
+	return o
+}
+
+// OpenURLObject represents an Open URL action.
+type OpenURLObject struct {
+	*object
+}
+
+// NewOpenURLObject returns an Open URL action opening url, which may contain {query}.
+func NewOpenURLObject(url string) *OpenURLObject {
+	return &OpenURLObject{newObject("alfred.workflow.action.openurl", 1, map[string]interface{}{
+		"browser": "",
+		"spaces":  "",
+		"url":     url,
+		"utf8":    true,
+	})}
+}
+
+// Browser sets the bundle ID of the browser opening the URL.
+func (o *OpenURLObject) Browser(bundleID string) *OpenURLObject {
+	o.config["browser"] = bundleID
+
+	return o
+}
+
+// ClipboardObject represents a Copy to Clipboard output.
+type ClipboardObject struct {
+	*object
+}
+
+// NewClipboardObject returns a Copy to Clipboard output copying text, which may contain {query}.
+func NewClipboardObject(text string) *ClipboardObject {
+	return &ClipboardObject{newObject("alfred.workflow.output.clipboard", 3, map[string]interface{}{ //nolint:gomnd // object version
+		"autopaste":                 false,
+		"clipboardtext":             text,
+		"ignoredynamicplaceholders": false,
+		"transient":                 false,
+	})}
+}
+
+// AutoPaste sets whether the text is pasted to the front most app.
+func (o *ClipboardObject) AutoPaste(paste bool) *ClipboardObject {
+	o.config["autopaste"] = paste
+
+	return o
+}
+
+// Transient sets whether the text is kept out of the clipboard history.
+func (o *ClipboardObject) Transient(transient bool) *ClipboardObject {
+	o.config["transient"] = transient
+
+	return o
+}
+
+// NotificationObject represents a Post Notification output.
+type NotificationObject struct {
+	*object
+}
+
+// NewNotificationObject returns a Post Notification output.
+func NewNotificationObject(title, text string) *NotificationObject {
+	return &NotificationObject{newObject("alfred.workflow.output.notification", 1, map[string]interface{}{
+		"lastpathcomponent":        false,
+		"onlyshowifquerypopulated": false,
+		"removeextension":          false,
+		"text":                     text,
+		"title":                    title,
+	})}
+}
+
+// OnlyShowIfQueryPopulated sets whether the notification is skipped for an empty query.
+func (o *NotificationObject) OnlyShowIfQueryPopulated(only bool) *NotificationObject {
+	o.config["onlyshowifquerypopulated"] = only
+
+	return o
+}
+
+// Condition represents a condition of a Conditional utility.
+type Condition struct {
+	uid           string
+	input         string
+	mode          ConditionMatchMode
+	match         string
+	label         string
+	caseSensitive bool
+}
+
+// NewCondition returns a Condition comparing input, which may contain {query} or {var:name}, with match.
+func NewCondition(input string, mode ConditionMatchMode, match string) *Condition {
+	return &Condition{
+		uid:   newObjectUID(),
+		input: input,
+		mode:  mode,
+		match: match,
+	}
+}
+
+// UID returns the identifier used to connect the output of the condition.
+func (c *Condition) UID() string {
+	return c.uid
+}
+
+// Label sets the output label.
+func (c *Condition) Label(label string) *Condition {
+	c.label = label
+
+	return c
+}
+
+// CaseSensitive sets whether the match is case sensitive.
+func (c *Condition) CaseSensitive(sensitive bool) *Condition {
+	c.caseSensitive = sensitive
+
+	return c
+}
+
+// ConditionalObject represents a Conditional utility.
+type ConditionalObject struct {
+	*object
+	conditions []*Condition
+}
+
+// NewConditionalObject returns a Conditional utility without conditions.
+func NewConditionalObject() *ConditionalObject {
+	return &ConditionalObject{object: newObject("alfred.workflow.utility.conditional", 1, map[string]interface{}{
+		"elselabel": "else",
+		"hideelse":  false,
+	})}
+}
+
+// Condition appends a condition; connect its output with Connection.SourceOutput.
+func (o *ConditionalObject) Condition(c *Condition) *ConditionalObject {
+	o.conditions = append(o.conditions, c)
+
+	return o
+}
+
+// ElseLabel sets the label of the else output.
+func (o *ConditionalObject) ElseLabel(label string) *ConditionalObject {
+	o.config["elselabel"] = label
+
+	return o
+}
+
+func (o *ConditionalObject) plist() map[string]interface{} {
+	conditions := make([]interface{}, 0, len(o.conditions))
+
+	for _, c := range o.conditions {
+		conditions = append(conditions, map[string]interface{}{
+			"inputstring":        c.input,
+			"matchcasesensitive": c.caseSensitive,
+			"matchmode":          int(c.mode),
+			"matchstring":        c.match,
+			"outputlabel":        c.label,
+			"uid":                c.uid,
+		})
+	}
+
+	p := o.object.plist()
+	config := make(map[string]interface{}, len(o.config)+1)
+
+	for key, value := range o.config {
+		config[key] = value
+	}
+
+	config["conditions"] = conditions
+	p["config"] = config
+
+	return p
+}
+
+// ArgVarsObject represents an Arg and Vars utility.
+type ArgVarsObject struct {
+	*object
+	variables map[string]string
+}
+
+// NewArgVarsObject returns an Arg and Vars utility setting the argument to arg, which may contain {query}.
+func NewArgVarsObject(arg string) *ArgVarsObject {
+	v := &ArgVarsObject{
+		object: newObject("alfred.workflow.utility.argument", 1, map[string]interface{}{
+			"argument":            arg,
+			"passthroughargument": false,
+		}),
+		variables: make(map[string]string),
+	}
+	v.config["variables"] = v.variables
+
+	return v
+}
+
+// Variable sets a variable passed to the connected objects.
+func (o *ArgVarsObject) Variable(key, value string) *ArgVarsObject {
+	o.variables[key] = value
+
+	return o
+}
+
+// ExternalTriggerObject represents an External Trigger.
+type ExternalTriggerObject struct {
+	*object
+}
+
+// NewExternalTriggerObject returns an External Trigger with the given trigger ID.
+func NewExternalTriggerObject(triggerID string) *ExternalTriggerObject {
+	return &ExternalTriggerObject{newObject("alfred.workflow.trigger.external", 1, map[string]interface{}{
+		"availableviaurlhandler": false,
+		"triggerid":              triggerID,
+	})}
+}
+
+// AvailableViaURLHandler sets whether the trigger can be run with an alfred:// URL.
+func (o *ExternalTriggerObject) AvailableViaURLHandler(available bool) *ExternalTriggerObject {
+	o.config["availableviaurlhandler"] = available
+
+	return o
+}
+
+// Connection represents a connection between two workflow objects.
+type Connection struct {
+	from            string
+	to              string
+	modifiers       ModifierKey
+	modifierSubtext string
+	sourceOutput    string
+}
+
+// Modifiers sets the modifier keys that must be held to follow the connection.
+func (c *Connection) Modifiers(mods ModifierKey) *Connection {
+	c.modifiers = mods
+
+	return c
+}
+
+// ModifierSubtext sets the subtext shown while the modifier keys are held.
+func (c *Connection) ModifierSubtext(subtext string) *Connection {
+	c.modifierSubtext = subtext
+
+	return c
+}
+
+// SourceOutput sets the output of the source object, e.g. a Condition UID, the connection starts from.
+func (c *Connection) SourceOutput(uid string) *Connection {
+	c.sourceOutput = uid
+
+	return c
+}
+
+func (c *Connection) plist() map[string]interface{} {
+	p := map[string]interface{}{
+		"destinationuid":  c.to,
+		"modifiers":       int(c.modifiers),
+		"modifiersubtext": c.modifierSubtext,
+		"vitoclose":       false,
+	}
+
+	if c.sourceOutput != "" {
+		p["sourceoutputuid"] = c.sourceOutput
+	}
+
+	return p
+}
+
+type position struct {
+	x, y int
+}
+
+// Workflow builds the info.plist of a workflow.
+type Workflow struct {
+	bundleID            string
+	name                string
+	version             string
+	createdBy           string
+	description         string
+	webAddress          string
+	readme              string
+	variables           map[string]string
+	variablesDontExport []string
+	objects             []Object
+	positions           map[string]position
+	connections         []*Connection
+	userConfig          *UserConfig
+}
+
+// NewWorkflow returns a Workflow with the given bundle ID and name.
+func NewWorkflow(bundleID, name string) *Workflow {
+	return &Workflow{
+		bundleID:  bundleID,
+		name:      name,
+		variables: make(map[string]string),
+		positions: make(map[string]position),
+	}
+}
+
+// Version sets the workflow version.
+func (wf *Workflow) Version(version string) *Workflow {
+	wf.version = version
+
+	return wf
+}
+
+// CreatedBy sets the workflow author.
+func (wf *Workflow) CreatedBy(author string) *Workflow {
+	wf.createdBy = author
+
+	return wf
+}
+
+// Description sets the workflow description.
+func (wf *Workflow) Description(description string) *Workflow {
+	wf.description = description
+
+	return wf
+}
+
+// WebAddress sets the workflow website.
+func (wf *Workflow) WebAddress(url string) *Workflow {
+	wf.webAddress = url
+
+	return wf
+}
+
+// Readme sets the About This Workflow text.
+func (wf *Workflow) Readme(readme string) *Workflow {
+	wf.readme = readme
+
+	return wf
+}
+
+// Variable sets a workflow environment variable.
+func (wf *Workflow) Variable(key, value string) *Workflow {
+	wf.variables[key] = value
+
+	return wf
+}
+
+// VariableDontExport sets a workflow environment variable whose value is not exported.
+func (wf *Workflow) VariableDontExport(key, value string) *Workflow {
+	wf.variables[key] = value
+	wf.variablesDontExport = append(wf.variablesDontExport, key)
+
+	return wf
+}
+
+// UserConfiguration sets the Configure Workflow sheet.
+func (wf *Workflow) UserConfiguration(config *UserConfig) *Workflow {
+	wf.userConfig = config
+
+	return wf
+}
+
+// Add adds objects laid out from left to right after the objects already added.
+func (wf *Workflow) Add(objects ...Object) *Workflow {
+	for _, o := range objects {
+		x := layoutOrigin + len(wf.objects)*layoutSpacing
+		wf.AddAt(o, x, layoutOrigin)
+	}
+
+	return wf
+}
+
+// AddAt adds an object at the given position of the workflow editor.
+func (wf *Workflow) AddAt(o Object, x, y int) *Workflow {
+	wf.objects = append(wf.objects, o)
+	wf.positions[o.UID()] = position{x: x, y: y}
+
+	return wf
+}
+
+// Connect connects the output of from to the input of to and returns the connection.
+func (wf *Workflow) Connect(from, to Object) *Connection {
+	c := &Connection{from: from.UID(), to: to.UID()}
+	wf.connections = append(wf.connections, c)
+
+	return c
+}
+
+// Plist returns the info.plist contents as a value accepted by EncodePlist.
+func (wf *Workflow) Plist() map[string]interface{} {
+	objects := make([]interface{}, 0, len(wf.objects))
+	for _, o := range wf.objects {
+		objects = append(objects, o.plist())
+	}
+
+	connections := make(map[string]interface{})
+
+	for _, c := range wf.connections {
+		list, _ := connections[c.from].([]interface{})
+		connections[c.from] = append(list, c.plist())
+	}
+
+	uidata := make(map[string]interface{}, len(wf.positions))
+	for uid, pos := range wf.positions {
+		uidata[uid] = map[string]interface{}{"xpos": pos.x, "ypos": pos.y}
+	}
+
+	p := map[string]interface{}{
+		"bundleid":    wf.bundleID,
+		"connections": connections,
+		"createdby":   wf.createdBy,
+		"description": wf.description,
+		"disabled":    false,
+		"name":        wf.name,
+		"objects":     objects,
+		"readme":      wf.readme,
+		"uidata":      uidata,
+		"version":     wf.version,
+		"webaddress":  wf.webAddress,
+	}
+
+	if len(wf.variables) > 0 {
+		p["variables"] = wf.variables
+	}
+
+	if len(wf.variablesDontExport) > 0 {
+		p["variablesdontexport"] = wf.variablesDontExport
+	}
+
+	if wf.userConfig != nil {
+		p["userconfigurationconfig"] = wf.userConfig.Plist()
+	}
+
+	return p
+}
+
+// Encode writes the info.plist to w.
+func (wf *Workflow) Encode(w io.Writer) error {
+	return EncodePlist(w, wf.Plist())
+}
+
+// WriteFile writes the info.plist to path.
+func (wf *Workflow) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create info.plist: %w", err)
+	}
+
+	if err := wf.Encode(f); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close info.plist: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,283 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestNewObjectUID(t *testing.T) {
+	t.Parallel()
+
+	re := regexp.MustCompile(`^[0-9A-F]{8}-[0-9A-F]{4}-4[0-9A-F]{3}-[89AB][0-9A-F]{3}-[0-9A-F]{12}$`)
+
+	seen := make(map[string]bool)
+
+	for i := 0; i < 10; i++ {
+		uid := newObjectUID()
+		if !re.MatchString(uid) {
+			t.Errorf("got: %s want: upper case UUID v4", uid)
+		}
+
+		if seen[uid] {
+			t.Errorf("got duplicated uid: %s", uid)
+		}
+
+		seen[uid] = true
+	}
+}
+
+func TestObjects_Plist(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		in     Object
+		typ    string
+		config map[string]interface{}
+	}
+
+	cond := NewCondition("{query}", MatchRegex, "^a").Label("starts with a").CaseSensitive(true)
+
+	tests := []Test{
+		{
+			in:  NewKeywordObject("kw").Title("Title").Subtext("Sub").ArgumentType(ArgumentNone),
+			typ: "alfred.workflow.input.keyword",
+			config: map[string]interface{}{
+				"argumenttype": 2, "keyword": "kw", "subtext": "Sub", "text": "Title", "withspace": true,
+			},
+		},
+		{
+			in: NewScriptFilterObject("sf", "./workflow").
+				Title("Title").
+				Subtext("Sub").
+				RunningSubtext("Loading").
+				ArgumentType(ArgumentRequired).
+				Language(ScriptZsh).
+				Escaping(0).
+				AlfredFiltersResults(true),
+			typ: "alfred.workflow.input.scriptfilter",
+			config: map[string]interface{}{
+				"alfredfiltersresults": true, "argumenttype": 0, "escaping": 0, "keyword": "sf",
+				"queuedelaycustom": 3, "queuedelayimmediatelyinitially": true, "queuedelaymode": 0, "queuemode": 1,
+				"runningsubtext": "Loading", "script": "./workflow", "scriptargtype": 1, "scriptfile": "",
+				"subtext": "Sub", "title": "Title", "type": 5, "withspace": true,
+			},
+		},
+		{
+			in:  NewRunScriptObject("./workflow run").Language(ScriptPython).Escaping(EscapeDoubleQuotes | EscapeBackslashes).Concurrently(true),
+			typ: "alfred.workflow.action.script",
+			config: map[string]interface{}{
+				"concurrently": true, "escaping": 68, "script": "./workflow run", "scriptargtype": 1,
+				"scriptfile": "", "type": 3,
+			},
+		},
+		{
+			in:     NewOpenURLObject("https://example.com/?q={query}").Browser("com.apple.Safari"),
+			typ:    "alfred.workflow.action.openurl",
+			config: map[string]interface{}{"browser": "com.apple.Safari", "spaces": "", "url": "https://example.com/?q={query}", "utf8": true},
+		},
+		{
+			in:  NewClipboardObject("{query}").AutoPaste(true).Transient(true),
+			typ: "alfred.workflow.output.clipboard",
+			config: map[string]interface{}{
+				"autopaste": true, "clipboardtext": "{query}", "ignoredynamicplaceholders": false, "transient": true,
+			},
+		},
+		{
+			in:  NewNotificationObject("Done", "{query}").OnlyShowIfQueryPopulated(true),
+			typ: "alfred.workflow.output.notification",
+			config: map[string]interface{}{
+				"lastpathcomponent": false, "onlyshowifquerypopulated": true, "removeextension": false,
+				"text": "{query}", "title": "Done",
+			},
+		},
+		{
+			in:  NewConditionalObject().Condition(cond).ElseLabel("other"),
+			typ: "alfred.workflow.utility.conditional",
+			config: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"inputstring": "{query}", "matchcasesensitive": true, "matchmode": 4,
+						"matchstring": "^a", "outputlabel": "starts with a", "uid": cond.UID(),
+					},
+				},
+				"elselabel": "other",
+				"hideelse":  false,
+			},
+		},
+		{
+			in:  NewArgVarsObject("{query}").Variable("key", "value"),
+			typ: "alfred.workflow.utility.argument",
+			config: map[string]interface{}{
+				"argument": "{query}", "passthroughargument": false, "variables": map[string]string{"key": "value"},
+			},
+		},
+		{
+			in:     NewExternalTriggerObject("trigger").AvailableViaURLHandler(true),
+			typ:    "alfred.workflow.trigger.external",
+			config: map[string]interface{}{"availableviaurlhandler": true, "triggerid": "trigger"},
+		},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(fmt.Sprintf("#%d:plist", i), func(t *testing.T) {
+			t.Parallel()
+			p := test.in.plist()
+			if p["type"] != test.typ {
+				t.Errorf("#%d: type: got: %v want: %s", i, p["type"], test.typ)
+			}
+			if p["uid"] != test.in.UID() {
+				t.Errorf("#%d: uid: got: %v want: %s", i, p["uid"], test.in.UID())
+			}
+			if !reflect.DeepEqual(p["config"], test.config) {
+				t.Errorf("#%d: config: got: %v want: %v", i, p["config"], test.config)
+			}
+		})
+	}
+}
+
+func TestWorkflow_Encode(t *testing.T) {
+	t.Parallel()
+
+	filter := NewScriptFilterObject("kw", "./workflow filter \"$1\"")
+	script := NewRunScriptObject("./workflow run \"$1\"")
+	copyText := NewClipboardObject("{query}")
+	trigger := NewExternalTriggerObject("search")
+
+	wf := NewWorkflow("com.example.test", "Test").
+		Version("1.0.0").
+		CreatedBy("youwkey").
+		Description("A test workflow").
+		WebAddress("https://example.com").
+		Readme("Readme").
+		Variable("limit", "10").
+		VariableDontExport("token", "secret").
+		Add(filter, script).
+		AddAt(copyText, 250, 200).
+		Add(trigger)
+
+	wf.Connect(filter, script)
+	wf.Connect(filter, copyText).Modifiers(ModifierCmd | ModifierShift).ModifierSubtext("Copy")
+	wf.Connect(trigger, filter)
+
+	var buf bytes.Buffer
+	if err := wf.Encode(&buf); err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+
+	info, err := DecodeWorkflowInfo(&buf)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+
+	if info.Name != "Test" || info.BundleID != "com.example.test" || info.Version != "1.0.0" ||
+		info.CreatedBy != "youwkey" || info.Description != "A test workflow" ||
+		info.WebAddress != "https://example.com" || info.Readme != "Readme" {
+		t.Errorf("metadata: got: %+v", info)
+	}
+
+	if want := map[string]string{"limit": "10", "token": "secret"}; !reflect.DeepEqual(info.Variables, want) {
+		t.Errorf("variables: got: %v want: %v", info.Variables, want)
+	}
+
+	if want := []string{"token"}; !reflect.DeepEqual(info.VariablesDontExport, want) {
+		t.Errorf("variablesdontexport: got: %v want: %v", info.VariablesDontExport, want)
+	}
+
+	if len(info.Objects) != 4 {
+		t.Fatalf("objects: got: %d want: 4", len(info.Objects))
+	}
+
+	for i, o := range []Object{filter, script, copyText, trigger} {
+		if info.Objects[i].UID != o.UID() {
+			t.Errorf("objects[%d]: got: %s want: %s", i, info.Objects[i].UID, o.UID())
+		}
+	}
+
+	p := wf.Plist()
+
+	connections, _ := p["connections"].(map[string]interface{})
+	fromFilter, _ := connections[filter.UID()].([]interface{})
+
+	if len(connections) != 2 || len(fromFilter) != 2 {
+		t.Fatalf("connections: got: %v", connections)
+	}
+
+	want := map[string]interface{}{
+		"destinationuid": copyText.UID(), "modifiers": 1179648, "modifiersubtext": "Copy", "vitoclose": false,
+	}
+	if !reflect.DeepEqual(fromFilter[1], want) {
+		t.Errorf("connection: got: %v want: %v", fromFilter[1], want)
+	}
+
+	uidata, _ := p["uidata"].(map[string]interface{})
+
+	positions := map[string][2]int{
+		filter.UID():   {50, 50},
+		script.UID():   {250, 50},
+		copyText.UID(): {250, 200},
+		trigger.UID():  {650, 50},
+	}
+
+	for uid, pos := range positions {
+		if want := map[string]interface{}{"xpos": pos[0], "ypos": pos[1]}; !reflect.DeepEqual(uidata[uid], want) {
+			t.Errorf("uidata[%s]: got: %v want: %v", uid, uidata[uid], want)
+		}
+	}
+}
+
+func TestWorkflow_ConnectConditional(t *testing.T) {
+	t.Parallel()
+
+	cond := NewCondition("{query}", MatchIsEqualTo, "yes")
+	conditional := NewConditionalObject().Condition(cond)
+	notify := NewNotificationObject("Yes", "")
+	wf := NewWorkflow("com.example.test", "Test").Add(conditional, notify)
+	wf.Connect(conditional, notify).SourceOutput(cond.UID())
+
+	connections, _ := wf.Plist()["connections"].(map[string]interface{})
+	list, _ := connections[conditional.UID()].([]interface{})
+
+	want := []interface{}{map[string]interface{}{
+		"destinationuid":  notify.UID(),
+		"modifiers":       0,
+		"modifiersubtext": "",
+		"sourceoutputuid": cond.UID(),
+		"vitoclose":       false,
+	}}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("got: %v want: %v", list, want)
+	}
+}
+
+func TestWorkflow_WriteFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "info.plist")
+	if err := NewWorkflow("com.example.test", "Test").WriteFile(path); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+
+	info, err := ReadWorkflowInfo(path)
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+
+	if info.BundleID != "com.example.test" {
+		t.Errorf("got: %s want: com.example.test", info.BundleID)
+	}
+
+	if err := NewWorkflow("", "").WriteFile(filepath.Join(t.TempDir(), "missing", "info.plist")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got: %v want: not exist error", err)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// WorkflowObject represents an object (input, action, output, utility or trigger) of a workflow.
+type WorkflowObject struct {
+	UID     string
+	Type    string
+	Version int64
+	Config  map[string]interface{}
+}
+
+// WorkflowInfo represents the metadata of a workflow stored in its info.plist.
+type WorkflowInfo struct {
+	Name                string
+	BundleID            string
+	Version             string
+	CreatedBy           string
+	Description         string
+	WebAddress          string
+	Readme              string
+	Disabled            bool
+	Variables           map[string]string
+	VariablesDontExport []string
+	Objects             []WorkflowObject
+	// UserConfiguration is the "Configure Workflow" definition, one dict per field.
+	UserConfiguration []map[string]interface{}
+}
+
+// ReadWorkflowInfo reads the info.plist at path.
+func ReadWorkflowInfo(path string) (*WorkflowInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open info.plist: %w", err)
+	}
+	defer f.Close()
+
+	return DecodeWorkflowInfo(f)
+}
+
+// DecodeWorkflowInfo decodes an info.plist from r.
+func DecodeWorkflowInfo(r io.Reader) (*WorkflowInfo, error) {
+	v, err := DecodePlist(r)
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: root is not a dict", ErrInvalidPlist)
+	}
+
+	info := &WorkflowInfo{
+		Name:                plistString(root, "name"),
+		BundleID:            plistString(root, "bundleid"),
+		Version:             plistString(root, "version"),
+		CreatedBy:           plistString(root, "createdby"),
+		Description:         plistString(root, "description"),
+		WebAddress:          plistString(root, "webaddress"),
+		Readme:              plistString(root, "readme"),
+		Variables:           make(map[string]string),
+		VariablesDontExport: make([]string, 0),
+		Objects:             make([]WorkflowObject, 0),
+		UserConfiguration:   make([]map[string]interface{}, 0),
+	}
+	info.Disabled, _ = root["disabled"].(bool)
+
+	if vars, ok := root["variables"].(map[string]interface{}); ok {
+		for key, value := range vars {
+			info.Variables[key] = fmt.Sprint(value)
+		}
+	}
+
+	for _, v := range plistArray(root, "variablesdontexport") {
+		if s, ok := v.(string); ok {
+			info.VariablesDontExport = append(info.VariablesDontExport, s)
+		}
+	}
+
+	for _, v := range plistArray(root, "objects") {
+		if obj, ok := v.(map[string]interface{}); ok {
+			o := WorkflowObject{UID: plistString(obj, "uid"), Type: plistString(obj, "type")}
+			o.Version, _ = obj["version"].(int64)
+			o.Config, _ = obj["config"].(map[string]interface{})
+			info.Objects = append(info.Objects, o)
+		}
+	}
+
+	for _, v := range plistArray(root, "userconfigurationconfig") {
+		if field, ok := v.(map[string]interface{}); ok {
+			info.UserConfiguration = append(info.UserConfiguration, field)
+		}
+	}
+
+	return info, nil
+}
+
+// ConfigDefaults returns the default value of each user configuration variable.
+func (wi *WorkflowInfo) ConfigDefaults() map[string]interface{} {
+	defaults := make(map[string]interface{})
+
+	for _, field := range wi.UserConfiguration {
+		name := plistString(field, "variable")
+		if name == "" {
+			continue
+		}
+
+		config, _ := field["config"].(map[string]interface{})
+		defaults[name] = config["default"]
+	}
+
+	return defaults
+}
+
+// Object returns the object with the given uid.
+func (wi *WorkflowInfo) Object(uid string) (WorkflowObject, bool) {
+	for _, o := range wi.Objects {
+		if o.UID == uid {
+			return o, true
+		}
+	}
+
+	return WorkflowObject{}, false
+}
+
+func plistString(dict map[string]interface{}, key string) string {
+	s, _ := dict[key].(string)
+
+	return s
+}
+
+func plistArray(dict map[string]interface{}, key string) []interface{} {
+	a, _ := dict[key].([]interface{})
+
+	return a
+}
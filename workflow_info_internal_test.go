@@ -0,0 +1,169 @@
+// Copyright 2021 youwkey. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package alfred
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testFullInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>bundleid</key>
+	<string>com.example.test</string>
+	<key>createdby</key>
+	<string>youwkey</string>
+	<key>description</key>
+	<string>A test workflow</string>
+	<key>disabled</key>
+	<true/>
+	<key>name</key>
+	<string>Test</string>
+	<key>objects</key>
+	<array>
+		<dict>
+			<key>config</key>
+			<dict>
+				<key>keyword</key>
+				<string>test</string>
+			</dict>
+			<key>type</key>
+			<string>alfred.workflow.input.scriptfilter</string>
+			<key>uid</key>
+			<string>UID-1</string>
+			<key>version</key>
+			<integer>3</integer>
+		</dict>
+	</array>
+	<key>userconfigurationconfig</key>
+	<array>
+		<dict>
+			<key>config</key>
+			<dict>
+				<key>default</key>
+				<string>token</string>
+			</dict>
+			<key>type</key>
+			<string>textfield</string>
+			<key>variable</key>
+			<string>api_key</string>
+		</dict>
+		<dict>
+			<key>config</key>
+			<dict>
+				<key>default</key>
+				<true/>
+			</dict>
+			<key>type</key>
+			<string>checkbox</string>
+			<key>variable</key>
+			<string>enabled</string>
+		</dict>
+	</array>
+	<key>variables</key>
+	<dict>
+		<key>secret</key>
+		<string>s3cr3t</string>
+		<key>limit</key>
+		<string>10</string>
+	</dict>
+	<key>variablesdontexport</key>
+	<array>
+		<string>secret</string>
+	</array>
+	<key>version</key>
+	<string>1.0.0</string>
+	<key>webaddress</key>
+	<string>https://example.com</string>
+</dict>
+</plist>
+`
+
+func TestDecodeWorkflowInfo(t *testing.T) {
+	t.Parallel()
+
+	info, err := DecodeWorkflowInfo(strings.NewReader(testFullInfoPlist))
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+
+	want := &WorkflowInfo{
+		Name:                "Test",
+		BundleID:            "com.example.test",
+		Version:             "1.0.0",
+		CreatedBy:           "youwkey",
+		Description:         "A test workflow",
+		WebAddress:          "https://example.com",
+		Disabled:            true,
+		Variables:           map[string]string{"secret": "s3cr3t", "limit": "10"},
+		VariablesDontExport: []string{"secret"},
+		Objects: []WorkflowObject{
+			{
+				UID:     "UID-1",
+				Type:    "alfred.workflow.input.scriptfilter",
+				Version: 3,
+				Config:  map[string]interface{}{"keyword": "test"},
+			},
+		},
+		UserConfiguration: info.UserConfiguration,
+	}
+
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("got: %+v want: %+v", info, want)
+	}
+
+	if len(info.UserConfiguration) != 2 {
+		t.Errorf("user configuration: got: %d fields want: 2", len(info.UserConfiguration))
+	}
+
+	defaults := map[string]interface{}{"api_key": "token", "enabled": true}
+	if got := info.ConfigDefaults(); !reflect.DeepEqual(got, defaults) {
+		t.Errorf("defaults: got: %v want: %v", got, defaults)
+	}
+
+	if o, ok := info.Object("UID-1"); !ok || o.Type != "alfred.workflow.input.scriptfilter" {
+		t.Errorf("object: got: %v, %t", o, ok)
+	}
+
+	if _, ok := info.Object("missing"); ok {
+		t.Errorf("object: got an object for an unknown uid")
+	}
+}
+
+func TestDecodeWorkflowInfo_NotDict(t *testing.T) {
+	t.Parallel()
+
+	if _, err := DecodeWorkflowInfo(strings.NewReader(`<plist><array/></plist>`)); !errors.Is(err, ErrInvalidPlist) {
+		t.Errorf("got: %v want: %v", err, ErrInvalidPlist)
+	}
+}
+
+func TestReadWorkflowInfo(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "info.plist")
+	if err := os.WriteFile(path, []byte(testFullInfoPlist), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := ReadWorkflowInfo(path)
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+
+	if info.BundleID != "com.example.test" {
+		t.Errorf("got: %s want: com.example.test", info.BundleID)
+	}
+
+	if _, err := ReadWorkflowInfo(filepath.Join(t.TempDir(), "missing.plist")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got: %v want: %v", err, os.ErrNotExist)
+	}
+}